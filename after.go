@@ -0,0 +1,39 @@
+package fastrouter
+
+import "github.com/valyala/fasthttp"
+
+// After 添加全局的后置处理函数，在每个请求处理完成后执行，包括PreHandler中止处理、处理函数panic
+// （Recover 处理或 ErrAbortHandler 中止请求之后）、404、405等没有匹配路由的请求以及交给主机路由处理的请求，
+// 可以用于添加响应头、记录审计日志和指标. 按添加顺序在路由和主机路由的后置处理函数之后执行.
+func (a *FastRouter) After(handler fasthttp.RequestHandler) *FastRouter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.afterHandlers = append(a.afterHandlers, handler)
+	a.rebuild()
+	return a
+}
+
+// After 添加路由的后置处理函数，匹配该路由的请求处理完成后执行，PreHandler中止处理或处理函数panic时同样执行.
+// Any 返回的句柄作用于全部请求方法的路由.
+func (r *Route) After(handler fasthttp.RequestHandler) *Route {
+	a := r.router
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, v := range r.routes() {
+		v.afterHandlers = append(v.afterHandlers, handler)
+	}
+	a.rebuild()
+	return r
+}
+
+// runAfter 依次执行匹配路由和全局的后置处理函数，matched 为nil时只执行全局的后置处理函数.
+func runAfter(ctx *fasthttp.RequestCtx, t *routeTable, matched *route) {
+	if matched != nil {
+		for j := range matched.afterHandlers {
+			matched.afterHandlers[j](ctx)
+		}
+	}
+	for j := range t.afterHandlers {
+		t.afterHandlers[j](ctx)
+	}
+}
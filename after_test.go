@@ -0,0 +1,76 @@
+package fastrouter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestRouterAfter(t *testing.T) {
+	var trace []string
+	after := func(name string) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			trace = append(trace, fmt.Sprintf("%s:%d", name, ctx.Response.StatusCode()))
+		}
+	}
+	router := NewRouter()
+	router.After(after("global")).After(after("audit"))
+	router.Get("/ok", func(ctx *fasthttp.RequestCtx) {}).After(after("route"))
+	router.Get("/private", func(ctx *fasthttp.RequestCtx) {}, BasicAuth("user", "pass")).After(after("route"))
+	router.Get("/panic", func(ctx *fasthttp.RequestCtx) {
+		panic("boom")
+	}).After(after("route"))
+	sub := NewRouter()
+	sub.After(after("sub"))
+	sub.Get("/", func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusAccepted)
+	})
+	router.Mount("/sub", sub)
+	router.Get("/abort", func(ctx *fasthttp.RequestCtx) {
+		panic(ErrAbortHandler)
+	}).After(after("route"))
+	api := router.Host("api.example.com")
+	api.After(after("api"))
+	api.Get("/users", func(ctx *fasthttp.RequestCtx) {})
+	h := router.Handler()
+
+	tests := []struct {
+		method, uri string
+		want        string
+	}{
+		{"GET", "/ok", "route:200 global:200 audit:200"},
+		{"HEAD", "/ok", "route:200 global:200 audit:200"},
+		{"GET", "/private", "route:401 global:401 audit:401"},
+		{"GET", "/panic", "route:500 global:500 audit:500"},
+		{"GET", "/nope", "global:404 audit:404"},
+		{"POST", "/ok", "global:405 audit:405"},
+		{"GET", "/sub", "sub:202 global:202 audit:202"},
+	}
+	for _, tt := range tests {
+		trace = nil
+		h(newTestCtx(tt.method, tt.uri))
+		if got := strings.Join(trace, " "); got != tt.want {
+			t.Errorf("%s %s: want %q, got %q", tt.method, tt.uri, tt.want, got)
+		}
+	}
+
+	// 主机路由处理的请求同样执行全局的后置处理函数.
+	trace = nil
+	ctx := newTestCtx("GET", "/users")
+	ctx.Request.SetHost("api.example.com")
+	h(ctx)
+	if got := strings.Join(trace, " "); got != "api:200 global:200 audit:200" {
+		t.Errorf("GET api.example.com/users: unexpected after-handlers %q", got)
+	}
+
+	// ErrAbortHandler 中止请求后同样执行后置处理函数.
+	trace = nil
+	if recv := catchPanic(func() { h(newTestCtx("GET", "/abort")) }); recv != nil {
+		t.Errorf("ErrAbortHandler should not be re-panicked, got %v", recv)
+	}
+	if got := strings.Join(trace, " "); got != "route:200 global:200 audit:200" {
+		t.Errorf("GET /abort: unexpected after-handlers %q", got)
+	}
+}
@@ -0,0 +1,206 @@
+package fastrouter
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BasicAuthUserKey 认证通过的用户名保存在 UserValue 中的键，也可以通过 BasicAuthUser 获取.
+const BasicAuthUserKey = "fastrouter.basicAuthUser"
+
+// BasicAuthConfig Basic认证配置，用户名和密码满足 Users、Htpasswd、Validator 任意一个即认证通过.
+type BasicAuthConfig struct {
+	// Users 用户名与明文密码.
+	Users map[string]string
+	// Htpasswd 用户名与htpasswd格式的密码哈希，可以由 LoadHtpasswd 读取.
+	// 支持bcrypt（"$2a$"、"$2b$"、"$2y$"）、"$apr1$" 和 "{SHA}" 哈希，包含其他哈希时 BasicAuthWithConfig panic.
+	Htpasswd map[string]string
+	// Validator 自定义的用户名和密码校验函数.
+	Validator func(ctx *fasthttp.RequestCtx, user, password string) bool
+	// Realm 认证失败时 WWW-Authenticate 头中的realm，为空时使用 "Restricted".
+	Realm string
+}
+
+// BasicAuthWithConfig 根据配置返回Basic认证的 PreHandler，认证通过后用户名保存在 UserValue 的 BasicAuthUserKey 中，
+// 认证失败时返回401. 密码使用常量时间比较.
+func BasicAuthWithConfig(config BasicAuthConfig) PreHandler {
+	for user, hash := range config.Htpasswd {
+		if !supportedHash(hash) {
+			panic(fmt.Sprintf("unsupported htpasswd hash for user %s", user))
+		}
+	}
+	if config.Realm == "" {
+		config.Realm = "Restricted"
+	}
+	users := make(map[string][sha256.Size]byte, len(config.Users))
+	for user, password := range config.Users {
+		users[user] = sha256.Sum256([]byte(password))
+	}
+	challenge := "Basic realm=" + strconv.Quote(config.Realm)
+	return func(ctx *fasthttp.RequestCtx) bool {
+		user, password, ok := parseBasicAuth(string(ctx.Request.Header.Peek("Authorization")))
+		if ok && config.validate(ctx, users, user, password) {
+			ctx.SetUserValue(BasicAuthUserKey, user)
+			return true
+		}
+		unauthorized(ctx, challenge)
+		return false
+	}
+}
+
+// validate 依次使用明文密码、htpasswd哈希和自定义校验函数校验用户名和密码.
+func (c *BasicAuthConfig) validate(ctx *fasthttp.RequestCtx, users map[string][sha256.Size]byte,
+	user, password string) bool {
+	sum := sha256.Sum256([]byte(password))
+	if want, ok := users[user]; ok && subtle.ConstantTimeCompare(sum[:], want[:]) == 1 {
+		return true
+	}
+	if hash, ok := c.Htpasswd[user]; ok && checkHash(hash, password) {
+		return true
+	}
+	return c.Validator != nil && c.Validator(ctx, user, password)
+}
+
+// checkHash 校验htpasswd格式的密码哈希，不支持的哈希格式校验失败.
+func checkHash(hash, password string) bool {
+	switch {
+	case isBcryptHash(hash):
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	case strings.HasPrefix(hash, apr1Magic):
+		salt := hash[len(apr1Magic):]
+		if i := strings.IndexByte(salt, '$'); i >= 0 {
+			salt = salt[:i]
+		}
+		return subtle.ConstantTimeCompare([]byte(hash), []byte(apr1(password, salt))) == 1
+	case strings.HasPrefix(hash, "{SHA}"):
+		sum := sha1.Sum([]byte(password))
+		want := base64.StdEncoding.EncodeToString(sum[:])
+		return subtle.ConstantTimeCompare([]byte(hash[len("{SHA}"):]), []byte(want)) == 1
+	}
+	return false
+}
+
+// supportedHash 判断是否支持该htpasswd哈希格式.
+func supportedHash(hash string) bool {
+	return isBcryptHash(hash) || strings.HasPrefix(hash, apr1Magic) || strings.HasPrefix(hash, "{SHA}")
+}
+
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+const apr1Magic = "$apr1$"
+
+// apr1 按Apache的MD5 crypt算法生成 "$apr1$salt$hash" 格式的哈希，salt 最多使用8个字符.
+func apr1(password, salt string) string {
+	if len(salt) > 8 {
+		salt = salt[:8]
+	}
+	pw := []byte(password)
+	h := md5.New()
+	h.Write(pw)
+	h.Write([]byte(apr1Magic + salt))
+	alt := md5.Sum([]byte(password + salt + password))
+	for i := len(pw); i > 0; i -= 16 {
+		if i > 16 {
+			h.Write(alt[:])
+		} else {
+			h.Write(alt[:i])
+		}
+	}
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			h.Write([]byte{0})
+		} else {
+			h.Write(pw[:1])
+		}
+	}
+	final := h.Sum(nil)
+	for i := 0; i < 1000; i++ {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(pw)
+		} else {
+			h.Write(final)
+		}
+		if i%3 != 0 {
+			h.Write([]byte(salt))
+		}
+		if i%7 != 0 {
+			h.Write(pw)
+		}
+		if i&1 != 0 {
+			h.Write(final)
+		} else {
+			h.Write(pw)
+		}
+		final = h.Sum(final[:0])
+	}
+	const itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	var b strings.Builder
+	b.WriteString(apr1Magic + salt + "$")
+	encode := func(v uint32, n int) {
+		for ; n > 0; n-- {
+			b.WriteByte(itoa64[v&0x3f])
+			v >>= 6
+		}
+	}
+	for _, i := range [][3]int{{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5}} {
+		encode(uint32(final[i[0]])<<16|uint32(final[i[1]])<<8|uint32(final[i[2]]), 4)
+	}
+	encode(uint32(final[11]), 2)
+	return b.String()
+}
+
+// BasicAuthUser 返回Basic认证通过的用户名，没有认证时返回空字符串.
+func BasicAuthUser(ctx *fasthttp.RequestCtx) string {
+	user, _ := ctx.UserValue(BasicAuthUserKey).(string)
+	return user
+}
+
+// LoadHtpasswd 读取htpasswd文件，返回用户名与密码哈希.
+func LoadHtpasswd(filename string) (map[string]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseHtpasswd(f)
+}
+
+// ParseHtpasswd 解析htpasswd格式的 "用户名:密码哈希" 行，忽略空行和以 "#" 开头的注释，
+// 包含不支持的哈希格式时返回错误.
+func ParseHtpasswd(r io.Reader) (map[string]string, error) {
+	users := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		i := strings.IndexByte(line, ':')
+		if i <= 0 || i == len(line)-1 {
+			return nil, fmt.Errorf("fastrouter: invalid htpasswd line %d", n)
+		}
+		if !supportedHash(line[i+1:]) {
+			return nil, fmt.Errorf("fastrouter: unsupported htpasswd hash on line %d", n)
+		}
+		users[line[:i]] = line[i+1:]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
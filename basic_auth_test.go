@@ -0,0 +1,80 @@
+package fastrouter
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestBasicAuthWithConfig(t *testing.T) {
+	htpasswd, err := ParseHtpasswd(strings.NewReader(`
+# users
+alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=
+bob:$2y$04$w/24nm.nDprTtYZUIYjevu06AtpevbM.4yd.NfpA4VViYbVVcZDVm
+carol:$apr1$r31.....$ARC3pREO82RIm0aQ2zszC0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := NewRouter()
+	router.Get("/private", func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(BasicAuthUser(ctx))
+	}, BasicAuthWithConfig(BasicAuthConfig{
+		Users:    map[string]string{"golang": "siki"},
+		Htpasswd: htpasswd,
+		Validator: func(ctx *fasthttp.RequestCtx, user, password string) bool {
+			return user == "token" && password == string(ctx.Request.Header.Peek("X-Token"))
+		},
+		Realm: "Admin Area",
+	}))
+	h := router.Handler()
+
+	tests := []struct {
+		user, password string
+		ok             bool
+	}{
+		{"golang", "siki", true},
+		{"golang", "sik", false},
+		{"alice", "password", true},
+		{"alice", "Password", false},
+		{"bob", "hunter2", true},
+		{"bob", "hunter3", false},
+		{"carol", "password", true},
+		{"carol", "passwore", false},
+		{"token", "t0k3n", true},
+		{"token", "", false},
+		{"nobody", "siki", false},
+	}
+	for _, tt := range tests {
+		ctx := newTestCtx("GET", "/private")
+		ctx.Request.Header.Set("X-Token", "t0k3n")
+		ctx.Request.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(tt.user+":"+tt.password)))
+		h(ctx)
+		if tt.ok && (ctx.Response.StatusCode() != 200 || string(ctx.Response.Body()) != tt.user) {
+			t.Errorf("%s:%s: want 200 %q, got %d %q", tt.user, tt.password, tt.user, ctx.Response.StatusCode(), ctx.Response.Body())
+		}
+		if !tt.ok && (ctx.Response.StatusCode() != 401 ||
+			string(ctx.Response.Header.Peek("WWW-Authenticate")) != `Basic realm="Admin Area"`) {
+			t.Errorf("%s:%s: want 401, got %d %q", tt.user, tt.password, ctx.Response.StatusCode(),
+				ctx.Response.Header.Peek("WWW-Authenticate"))
+		}
+	}
+
+	ctx := newTestCtx("GET", "/private")
+	h(ctx)
+	if ctx.Response.StatusCode() != 401 {
+		t.Errorf("missing Authorization: want 401, got %d", ctx.Response.StatusCode())
+	}
+	for _, htpasswd := range []string{"alice\n", "alice:$5$rounds=5000$salt$hash\n", "alice:plaintext\n"} {
+		if _, err := ParseHtpasswd(strings.NewReader(htpasswd)); err == nil {
+			t.Errorf("%q: want error for invalid htpasswd line", htpasswd)
+		}
+	}
+	if recv := catchPanic(func() {
+		BasicAuthWithConfig(BasicAuthConfig{Htpasswd: map[string]string{"alice": "$6$salt$hash"}})
+	}); recv == nil {
+		t.Error("unsupported htpasswd hash should panic")
+	}
+}
@@ -0,0 +1,162 @@
+package fastrouter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ConstraintFunc 校验路由变量的值，返回false时该路由片段不匹配.
+type ConstraintFunc func(value []byte) bool
+
+// defaultConstraints 内置的路由变量约束，可以在路由中使用 ":id<int>" 的形式引用.
+var defaultConstraints = map[string]ConstraintFunc{
+	"int":   isInt,
+	"uint":  isUint,
+	"alpha": isAlpha,
+	"alnum": isAlnum,
+	"hex":   isHex,
+	"uuid":  isUUID,
+}
+
+// RegisterConstraint 注册自定义的路由变量约束，需要在使用该约束的路由之前注册.
+func (a *FastRouter) RegisterConstraint(name string, match ConstraintFunc) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !isConstraintName(name) {
+		panic(fmt.Sprintf("invalid constraint name: %s", name))
+	}
+	a.constraints[name] = match
+}
+
+// parseParam 解析变量片段，"/:id<int>" 返回 ("id", "int").
+func parseParam(seg string) (name, expr string, err error) {
+	name = seg[2:]
+	i := strings.IndexByte(name, '<')
+	if i < 0 {
+		return name, "", nil
+	}
+	if name[len(name)-1] != '>' || i == len(name)-2 {
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidConstraint, seg)
+	}
+	return name[:i], name[i+1 : len(name)-1], nil
+}
+
+// constraint 根据约束表达式返回校验函数，表达式可以是已注册的约束名或正则表达式.
+func (a *FastRouter) constraint(expr string) (ConstraintFunc, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	if isConstraintName(expr) {
+		match, ok := a.constraints[expr]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown constraint %s", ErrInvalidConstraint, expr)
+		}
+		return match, nil
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %s", ErrInvalidConstraint, expr, err)
+	}
+	return re.Match, nil
+}
+
+func isConstraintName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(isAlnumChar(c) || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func isInt(b []byte) bool {
+	if len(b) > 1 && b[0] == '-' {
+		b = b[1:]
+	}
+	return isUint(b)
+}
+
+func isUint(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlpha(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	for _, c := range b {
+		if !isAlphaChar(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlnum(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	for _, c := range b {
+		if !isAlnumChar(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func isHex(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	for _, c := range b {
+		if !isHexChar(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID 校验 8-4-4-4-12 格式的UUID.
+func isUUID(b []byte) bool {
+	if len(b) != 36 {
+		return false
+	}
+	for i, c := range b {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !isHexChar(c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isAlphaChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isAlnumChar(c byte) bool {
+	return isAlphaChar(c) || (c >= '0' && c <= '9')
+}
+
+func isHexChar(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,87 @@
+package fastrouter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestConstraint(t *testing.T) {
+	router := NewRouter()
+	router.RegisterConstraint("lang", func(value []byte) bool {
+		return bytes.Equal(value, []byte("en")) || bytes.Equal(value, []byte("zh"))
+	})
+	var matched string
+	handle := func(path string) {
+		router.Get(path, func(ctx *fasthttp.RequestCtx) {
+			matched = path
+		})
+	}
+	handle("/users/:id<int>")
+	handle("/users/:uuid<uuid>")
+	handle("/users/:name")
+	handle("/posts/:slug<[a-z0-9-]+>")
+	handle("/docs/:lang<lang>/:page")
+
+	tests := map[string]string{
+		"/users/42": "/users/:id<int>",
+		"/users/-1": "/users/:id<int>",
+		"/users/0b7c9f3c-2a6e-4f1e-9d7c-5c3a2b1e0f9a": "/users/:uuid<uuid>",
+		"/users/gopher":        "/users/:name",
+		"/posts/hello-world-2": "/posts/:slug<[a-z0-9-]+>",
+		"/posts/Hello":         "",
+		"/docs/zh/intro":       "/docs/:lang<lang>/:page",
+		"/docs/fr/intro":       "",
+	}
+	handler := router.Handler()
+	for uri, want := range tests {
+		matched = ""
+		ctx := newTestCtx("GET", uri)
+		handler(ctx)
+		if matched != want {
+			t.Errorf("GET %s: want %q, got %q", uri, want, matched)
+		}
+		if want == "" && ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+			t.Errorf("GET %s: want 404, got %d", uri, ctx.Response.StatusCode())
+		}
+	}
+
+	ctx := newTestCtx("GET", "/users/42")
+	handler(ctx)
+	if ctx.UserValue("id") != "42" {
+		t.Errorf("wrong variable value: %v", ctx.UserValue("id"))
+	}
+
+	for _, path := range []string{"/a/:id<unknown>", "/a/:id<[a-z>", "/a/:id<>", "/a/:id<int"} {
+		if recv := catchPanic(func() { router.Get(path, nil) }); recv == nil {
+			t.Errorf("registering %s did not panic", path)
+		}
+	}
+}
+
+func TestBuiltinConstraints(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{"int", "123", true},
+		{"int", "-123", true},
+		{"int", "-", false},
+		{"int", "1a", false},
+		{"uint", "-1", false},
+		{"alpha", "abcXYZ", true},
+		{"alpha", "abc1", false},
+		{"alnum", "abc1", true},
+		{"hex", "0aF9", true},
+		{"hex", "0g", false},
+		{"uuid", "0B7C9F3C-2A6E-4F1E-9D7C-5C3A2B1E0F9A", true},
+		{"uuid", "0b7c9f3c2a6e4f1e9d7c5c3a2b1e0f9a", false},
+	}
+	for _, tt := range tests {
+		if got := defaultConstraints[tt.name]([]byte(tt.value)); got != tt.want {
+			t.Errorf("%s(%q): want %v, got %v", tt.name, tt.value, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package fastrouter
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// CORSConfig CORS 配置，来源（Origin）满足 AllowOrigins、AllowOriginRegexps、AllowOriginFunc 任意一个即允许.
+type CORSConfig struct {
+	// AllowOrigins 允许的来源，如 "https://example.com"，忽略大小写.
+	// "https://*.example.com" 允许该域名的任意子域名，"*" 允许任意来源.
+	AllowOrigins []string
+	// AllowOriginRegexps 允许的来源的正则表达式.
+	AllowOriginRegexps []*regexp.Regexp
+	// AllowOriginFunc 自定义的来源校验函数.
+	AllowOriginFunc func(origin string) bool
+	// AllowMethods 预检请求允许的请求方法，为空时使用路由中该路径实际注册的请求方法.
+	AllowMethods []string
+	// AllowHeaders 预检请求允许的请求头，为空时允许预检请求中的 Access-Control-Request-Headers.
+	AllowHeaders []string
+	// ExposeHeaders 允许客户端读取的响应头.
+	ExposeHeaders []string
+	// AllowCredentials 是否允许携带凭证，不能与允许任意来源的 "*" 同时使用.
+	AllowCredentials bool
+	// MaxAge 预检请求结果的缓存时间（秒），为0时不返回 Access-Control-Max-Age.
+	MaxAge int
+	// AllowPrivateNetwork 是否允许公网页面访问私有网络（Private Network Access 预检请求）.
+	AllowPrivateNetwork bool
+}
+
+// cors 由 CORSConfig 生成的CORS处理，响应头在创建时生成.
+type cors struct {
+	allowAll       bool
+	origins        map[string]struct{}
+	wildcards      [][2]string // 通配子域名的来源，分别为 "*" 前后的部分
+	regexps        []*regexp.Regexp
+	originFunc     func(origin string) bool
+	methods        string
+	headers        string
+	exposeHeaders  string
+	credentials    bool
+	maxAge         string
+	privateNetwork bool
+}
+
+// CORS 根据配置返回处理跨域请求的 PreHandler，AllowOrigins 包含 "*" 且 AllowCredentials 为true时panic.
+// 允许的来源返回对应的CORS响应头，不允许的来源不返回CORS响应头，响应始终包含 "Vary: Origin".
+// 预检请求（带有 Access-Control-Request-Method 的OPTIONS请求）由 CORS 直接返回204，不允许的来源返回403.
+// 作为路由或分组的PreHandler时，自动响应的预检请求只执行该路径上路由中的 CORS，不执行其他PreHandler，
+// CORS 需要直接作为PreHandler注册，包装后不能被识别；作为全局PreHandler时需开启 UseOnUnmatched.
+func CORS(config CORSConfig) PreHandler {
+	c := &cors{
+		origins:        map[string]struct{}{},
+		regexps:        config.AllowOriginRegexps,
+		originFunc:     config.AllowOriginFunc,
+		methods:        strings.Join(config.AllowMethods, ", "),
+		headers:        strings.Join(config.AllowHeaders, ", "),
+		exposeHeaders:  strings.Join(config.ExposeHeaders, ", "),
+		credentials:    config.AllowCredentials,
+		privateNetwork: config.AllowPrivateNetwork,
+	}
+	for _, origin := range config.AllowOrigins {
+		origin = strings.ToLower(origin)
+		if origin == "*" {
+			if config.AllowCredentials {
+				panic("AllowOrigins \"*\" cannot be used with AllowCredentials")
+			}
+			c.allowAll = true
+		} else if i := strings.IndexByte(origin, '*'); i >= 0 {
+			c.wildcards = append(c.wildcards, [2]string{origin[:i], origin[i+1:]})
+		} else {
+			c.origins[origin] = struct{}{}
+		}
+	}
+	if config.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(config.MaxAge)
+	}
+	return c.handle
+}
+
+// corsHandler CORS 返回的PreHandler的函数指针，所有 cors 的 handle 方法值相同.
+var corsHandler = reflect.ValueOf((&cors{}).handle).Pointer()
+
+// isCORS 判断PreHandler是否由 CORS 返回.
+func isCORS(h PreHandler) bool {
+	return reflect.ValueOf(h).Pointer() == corsHandler
+}
+
+func (c *cors) handle(ctx *fasthttp.RequestCtx) bool {
+	ctx.Response.Header.Add("Vary", "Origin")
+	origin := ctx.Request.Header.Peek("Origin")
+	if len(origin) == 0 {
+		return true
+	}
+	preflight := ctx.IsOptions() && len(ctx.Request.Header.Peek("Access-Control-Request-Method")) > 0
+	if !c.allowOrigin(string(origin)) {
+		if preflight {
+			ctx.SetStatusCode(fasthttp.StatusForbidden)
+			return false
+		}
+		return true
+	}
+	h := &ctx.Response.Header
+	if c.allowAll {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.SetBytesV("Access-Control-Allow-Origin", origin)
+	}
+	if c.credentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if !preflight {
+		if c.exposeHeaders != "" {
+			h.Set("Access-Control-Expose-Headers", c.exposeHeaders)
+		}
+		return true
+	}
+	if methods := c.allowMethods(ctx); methods != "" {
+		h.Set("Access-Control-Allow-Methods", methods)
+	}
+	if c.headers != "" {
+		h.Set("Access-Control-Allow-Headers", c.headers)
+	} else if headers := ctx.Request.Header.Peek("Access-Control-Request-Headers"); len(headers) > 0 {
+		h.SetBytesV("Access-Control-Allow-Headers", headers)
+	}
+	if c.maxAge != "" {
+		h.Set("Access-Control-Max-Age", c.maxAge)
+	}
+	if c.privateNetwork && bytes.Equal(ctx.Request.Header.Peek("Access-Control-Request-Private-Network"), []byte("true")) {
+		h.Set("Access-Control-Allow-Private-Network", "true")
+	}
+	ctx.SetStatusCode(fasthttp.StatusNoContent)
+	return false
+}
+
+// allowOrigin 判断来源是否允许.
+func (c *cors) allowOrigin(origin string) bool {
+	if c.allowAll {
+		return true
+	}
+	lower := strings.ToLower(origin)
+	if _, ok := c.origins[lower]; ok {
+		return true
+	}
+	for _, w := range c.wildcards {
+		if len(lower) > len(w[0])+len(w[1]) && strings.HasPrefix(lower, w[0]) && strings.HasSuffix(lower, w[1]) {
+			return true
+		}
+	}
+	for _, re := range c.regexps {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return c.originFunc != nil && c.originFunc(origin)
+}
+
+// allowMethods 返回预检请求允许的请求方法，没有配置时使用处理请求的路由中该路径实际注册的请求方法.
+func (c *cors) allowMethods(ctx *fasthttp.RequestCtx) string {
+	if c.methods != "" {
+		return c.methods
+	}
+	a := routerFromCtx(ctx)
+	urlPath, ok := ctx.UserValue(routePathKey).([]byte)
+	if a == nil || !ok {
+		return ""
+	}
+	return a.allowed(a.loadTable(), urlPath, http.MethodOptions)
+}
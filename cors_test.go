@@ -0,0 +1,185 @@
+package fastrouter
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestCORS(t *testing.T) {
+	handlerFunc := func(ctx *fasthttp.RequestCtx) {}
+	router := NewRouter()
+	router.UseOnUnmatched = true
+	router.Use(CORS(CORSConfig{
+		AllowOrigins:        []string{"https://example.com", "https://*.example.org"},
+		AllowOriginRegexps:  []*regexp.Regexp{regexp.MustCompile(`^http://localhost:\d+$`)},
+		AllowOriginFunc:     func(origin string) bool { return origin == "https://partner.com" },
+		ExposeHeaders:       []string{"X-Total-Count"},
+		AllowCredentials:    true,
+		MaxAge:              600,
+		AllowPrivateNetwork: true,
+	}))
+	router.Get("/users/:id", handlerFunc)
+	router.Put("/users/:id", handlerFunc)
+	router.Delete("/users/:id", handlerFunc)
+	h := router.Handler()
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://example.com", true},
+		{"HTTPS://EXAMPLE.COM", true},
+		{"https://api.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"http://api.example.org", false},
+		{"http://localhost:3000", true},
+		{"https://partner.com", true},
+		{"https://evil.com", false},
+	}
+	for _, tt := range tests {
+		ctx := newTestCtx("GET", "/users/1")
+		ctx.Request.Header.Set("Origin", tt.origin)
+		h(ctx)
+		got := string(ctx.Response.Header.Peek("Access-Control-Allow-Origin"))
+		if tt.allowed && (got != tt.origin || string(ctx.Response.Header.Peek("Access-Control-Allow-Credentials")) != "true" ||
+			string(ctx.Response.Header.Peek("Access-Control-Expose-Headers")) != "X-Total-Count") {
+			t.Errorf("%s: want allowed, got %q", tt.origin, got)
+		}
+		if !tt.allowed && got != "" {
+			t.Errorf("%s: want not allowed, got %q", tt.origin, got)
+		}
+		if ctx.Response.StatusCode() != fasthttp.StatusOK {
+			t.Errorf("%s: want request handled, got %d", tt.origin, ctx.Response.StatusCode())
+		}
+		if string(ctx.Response.Header.Peek("Vary")) != "Origin" {
+			t.Errorf("%s: want Vary: Origin", tt.origin)
+		}
+	}
+
+	// 预检请求使用该路径实际注册的请求方法.
+	ctx := newTestCtx("OPTIONS", "/users/1")
+	ctx.Request.Header.Set("Origin", "https://example.com")
+	ctx.Request.Header.Set("Access-Control-Request-Method", "PUT")
+	ctx.Request.Header.Set("Access-Control-Request-Headers", "Content-Type, X-Token")
+	ctx.Request.Header.Set("Access-Control-Request-Private-Network", "true")
+	h(ctx)
+	for key, want := range map[string]string{
+		"Access-Control-Allow-Origin":          "https://example.com",
+		"Access-Control-Allow-Methods":         "DELETE, GET, HEAD, OPTIONS, PUT",
+		"Access-Control-Allow-Headers":         "Content-Type, X-Token",
+		"Access-Control-Max-Age":               "600",
+		"Access-Control-Allow-Private-Network": "true",
+		"Access-Control-Allow-Credentials":     "true",
+		"Access-Control-Expose-Headers":        "",
+	} {
+		if got := string(ctx.Response.Header.Peek(key)); got != want {
+			t.Errorf("preflight %s: want %q, got %q", key, want, got)
+		}
+	}
+	if ctx.Response.StatusCode() != fasthttp.StatusNoContent {
+		t.Errorf("preflight: want 204, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = newTestCtx("OPTIONS", "/users/1")
+	ctx.Request.Header.Set("Origin", "https://evil.com")
+	ctx.Request.Header.Set("Access-Control-Request-Method", "PUT")
+	h(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusForbidden || len(ctx.Response.Header.Peek("Access-Control-Allow-Methods")) > 0 {
+		t.Errorf("preflight from disallowed origin: want 403, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestCORSAllowAll(t *testing.T) {
+	tests := []struct {
+		config CORSConfig
+		want   string
+	}{
+		{CORSConfig{AllowOrigins: []string{"*"}, AllowMethods: []string{"GET", "POST"}, AllowHeaders: []string{"Content-Type"}}, "*"},
+	}
+	for _, tt := range tests {
+		router := NewRouter()
+		router.Options("/", func(ctx *fasthttp.RequestCtx) {}, CORS(tt.config))
+		ctx := newTestCtx("OPTIONS", "/")
+		ctx.Request.Header.Set("Origin", "https://example.com")
+		ctx.Request.Header.Set("Access-Control-Request-Method", "POST")
+		ctx.Request.Header.Set("Access-Control-Request-Headers", "X-Token")
+		router.Handler()(ctx)
+		got := []string{
+			string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")),
+			string(ctx.Response.Header.Peek("Access-Control-Allow-Methods")),
+			string(ctx.Response.Header.Peek("Access-Control-Allow-Headers")),
+		}
+		if want := tt.want + "|GET, POST|Content-Type"; strings.Join(got, "|") != want {
+			t.Errorf("credentials %v: want %q, got %q", tt.config.AllowCredentials, want, strings.Join(got, "|"))
+		}
+	}
+
+	// 允许任意来源时不能携带凭证，否则相当于信任任意来源的凭证.
+	config := CORSConfig{AllowOrigins: []string{"https://example.com", "*"}, AllowCredentials: true}
+	if recv := catchPanic(func() { CORS(config) }); recv == nil {
+		t.Error(`CORS with AllowOrigins "*" and AllowCredentials did not panic`)
+	}
+}
+
+func TestCORSRoutePreflight(t *testing.T) {
+	handlerFunc := func(ctx *fasthttp.RequestCtx) {}
+	cors := CORS(CORSConfig{AllowOrigins: []string{"https://example.com"}})
+	router := NewRouter()
+	api := router.Group("/api", cors)
+	api.Get("/users/:id", handlerFunc)
+	api.Put("/users/:id", handlerFunc)
+	router.Get("/files/:name", handlerFunc)
+	router.Options("/files/:name", handlerFunc, cors)
+	var audited bool
+	secure := router.Group("/secure", BasicAuth("user", "pass"), func(ctx *fasthttp.RequestCtx) bool {
+		audited = true
+		return true
+	})
+	secure.Put("/users/:id", handlerFunc, cors)
+	h := router.Handler()
+
+	tests := []struct {
+		uri, methods string
+	}{
+		// 自动响应的OPTIONS请求执行分组的 CORS.
+		{"/api/users/1", "GET, HEAD, OPTIONS, PUT"},
+		// 使用路由匹配的路径，而不是 fasthttp 解码后的路径.
+		{"/files/a%2Fb", "GET, HEAD, OPTIONS"},
+	}
+	for _, tt := range tests {
+		ctx := newTestCtx("OPTIONS", tt.uri)
+		ctx.Request.Header.Set("Origin", "https://example.com")
+		ctx.Request.Header.Set("Access-Control-Request-Method", "PUT")
+		h(ctx)
+		if ctx.Response.StatusCode() != fasthttp.StatusNoContent ||
+			string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")) != "https://example.com" ||
+			string(ctx.Response.Header.Peek("Access-Control-Allow-Methods")) != tt.methods {
+			t.Errorf("preflight %s: want 204 with methods %q, got %d %q", tt.uri, tt.methods,
+				ctx.Response.StatusCode(), ctx.Response.Header.Peek("Access-Control-Allow-Methods"))
+		}
+	}
+
+	// 预检请求只执行路由中的 CORS，不执行认证等其他PreHandler，也不写入路由变量.
+	ctx := newTestCtx("OPTIONS", "/secure/users/1")
+	ctx.Request.Header.Set("Origin", "https://example.com")
+	ctx.Request.Header.Set("Access-Control-Request-Method", "PUT")
+	h(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusNoContent ||
+		string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")) != "https://example.com" {
+		t.Errorf("preflight behind BasicAuth: want 204, got %d", ctx.Response.StatusCode())
+	}
+	if audited || ctx.UserValue("id") != nil {
+		t.Errorf("preflight should only run CORS, audited=%v id=%v", audited, ctx.UserValue("id"))
+	}
+
+	// 不是预检请求的OPTIONS请求仍然自动响应.
+	ctx = newTestCtx("OPTIONS", "/api/users/1")
+	h(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusNoContent || string(ctx.Response.Header.Peek("Allow")) != "GET, HEAD, OPTIONS, PUT" {
+		t.Errorf("OPTIONS: want 204 with Allow, got %d %q", ctx.Response.StatusCode(), ctx.Response.Header.Peek("Allow"))
+	}
+}
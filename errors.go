@@ -0,0 +1,16 @@
+package fastrouter
+
+import "errors"
+
+// 注册路由时返回的错误，TryHandle 等方法返回包装了这些错误的error，可以通过 errors.Is 判断，
+// Handle、Get 等方法使用相同的error panic.
+var (
+	// ErrInvalidPath 路由路径不合法，如为空、不以"/"开头、变量名为空或通配变量不在最后.
+	ErrInvalidPath = errors.New("fastrouter: invalid route path")
+	// ErrDuplicateRoute 相同请求方法下已存在相同路径模式的路由.
+	ErrDuplicateRoute = errors.New("fastrouter: route already exists")
+	// ErrDuplicateParam 同一路由中的变量名重复.
+	ErrDuplicateParam = errors.New("fastrouter: duplicate route variable name")
+	// ErrInvalidConstraint 变量约束不合法，如约束未注册或正则表达式无法编译.
+	ErrInvalidConstraint = errors.New("fastrouter: invalid route variable constraint")
+)
@@ -1,10 +1,13 @@
 package fastrouter
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/valyala/fasthttp"
 )
@@ -12,29 +15,162 @@ import (
 type PreHandler func(ctx *fasthttp.RequestCtx) bool
 
 type FastRouter struct {
-	mu          sync.Mutex
-	indexRoutes map[string][]*route
-	routes      []*route
-	NotFound    fasthttp.RequestHandler
-	NotAllowed  fasthttp.RequestHandler
-	Recover     func(ctx *fasthttp.RequestCtx, p interface{})
-	preHandlers []PreHandler
-}
-
-func defaultRecover(ctx *fasthttp.RequestCtx, p interface{}) {
-	ctx.Error(fmt.Sprintf("%v", p), http.StatusInternalServerError)
+	mu            sync.Mutex
+	table         atomic.Value // *routeTable，处理请求时使用的只读路由表
+	published     bool         // 路由表是否已发布，见 publish
+	trees         map[string]*node
+	routes        []*route
+	preHandlers   []PreHandler
+	middlewares   []Middleware
+	afterHandlers []fasthttp.RequestHandler // 全局的后置处理函数
+	preRouting    []PreHandler              // 匹配路由之前执行的处理函数
+	constraints   map[string]ConstraintFunc
+	groups        []*Group
+	mounts        []*route
+	mountTree     *node // 只包含挂载路由，用于没有注册过路由的请求方法
+	names         map[string]*route
+	hosts         map[string]*FastRouter // 确定主机名的路由表
+	hostPatterns  []*hostPattern         // 带变量主机名的路由表，按注册顺序匹配
+	NotFound      fasthttp.RequestHandler
+	NotAllowed    fasthttp.RequestHandler
+	// Recover 处理请求中发生的panic，为nil时返回500. ErrAbortHandler 不会交给 Recover 处理，而是中止请求并关闭连接.
+	Recover func(ctx *fasthttp.RequestCtx, info *PanicInfo)
+	// Logger 不为nil时记录请求中发生的panic及其调用栈.
+	Logger fasthttp.Logger
+	// HandleHEAD 为true时，没有HEAD路由的HEAD请求使用GET路由处理，并且不返回响应体.
+	HandleHEAD bool
+	// HandleOPTIONS 为true时，没有OPTIONS路由的OPTIONS请求自动返回包含Allow头的204响应.
+	HandleOPTIONS bool
+	// GlobalOPTIONS 自动处理OPTIONS请求时调用，此时Allow头已经设置.
+	GlobalOPTIONS fasthttp.RequestHandler
+	// RedirectTrailingSlash 为true时，请求路径只相差末尾的"/"能匹配路由时重定向到注册的路径.
+	RedirectTrailingSlash bool
+	// RedirectFixedPath 为true时，忽略大小写能匹配路由时重定向到注册的路径.
+	RedirectFixedPath bool
+	// RedirectCleanPath 为true时，请求路径不规范（包含"//"、"."或".."片段等）时重定向到 CleanPath 规范化后的路径，
+	// 否则直接使用规范化后的路径匹配路由. 只对 Handler 所属的路由生效.
+	RedirectCleanPath bool
+	// SetUserValues 为true时路由变量同时通过 ctx.SetUserValue 写入，兼容使用 ctx.UserValue 获取变量的代码，
+	// 为false时只能通过 ParamsFromCtx 获取，匹配变量路由不产生内存分配. 写入的变量值是独立分配的字符串.
+	SetUserValues bool
+	// ErrorHandler 处理 HandlerE 返回的错误，为nil时 HTTPError 返回其状态码和信息，其他错误返回500.
+	ErrorHandler func(ctx *fasthttp.RequestCtx, err error)
+	// UseOnUnmatched 为true时，Use 和 UseMiddleware 添加的全局PreHandler和中间件同样作用于没有匹配路由的请求
+	// （重定向、自动处理的OPTIONS请求、405和404），例如让 CorsHandler 为错误响应添加CORS头.
+	UseOnUnmatched bool
+	// ProblemDetails 为true时，路由产生的错误（404、405、414、BasicAuth的401、panic的500、HandlerE的错误）
+	// 在客户端接受JSON时输出为 RFC 7807 的 application/problem+json，否则仍输出文本.
+	ProblemDetails bool
 }
 
 type route struct {
 	deepPath        []string
-	varsN           map[string]int
-	prefix          string
+	vars            []routeVar
 	urlPath         string
+	pattern         string // 忽略变量名的路径模式，相同模式的路由共享允许的请求方法
 	method          string
-	allowMethods    map[string]struct{}
+	allowMethods    *methodSet
 	isPrefixHandler bool
 	preHandlers     []PreHandler
+	afterHandlers   []fasthttp.RequestHandler
+	middlewares     []Middleware // 分组和路由的中间件，生成路由表时在全局中间件内层组合
 	handler         fasthttp.RequestHandler
+	mount           *FastRouter // 挂载的子路由
+	mountDepth      int         // 挂载前缀的片段数
+}
+
+// routeVar 路由变量名及其所在的路径片段位置.
+type routeVar struct {
+	index    int
+	name     string
+	expr     string         // 约束表达式，如 ":id<int>" 中的 "int"
+	check    ConstraintFunc // 约束校验函数
+	catchAll bool           // 通配变量，值为该片段起的剩余路径
+}
+
+// methodSet 相同路径模式的路由共享的请求方法集合.
+type methodSet struct {
+	methods map[string]struct{}
+	// allows 注册时生成的排序后的Allow响应头，按是否自动处理HEAD、OPTIONS请求区分，见 allowIndex.
+	allows [4]string
+}
+
+func newMethodSet(method string) *methodSet {
+	s := &methodSet{methods: map[string]struct{}{}}
+	s.add(method)
+	return s
+}
+
+func (s *methodSet) add(method string) {
+	s.methods[method] = struct{}{}
+	for i := range s.allows {
+		s.allows[i] = buildAllow(s.methods, i)
+	}
+}
+
+// buildAllow 生成排序后的Allow响应头，i 为 allowIndex 返回的自动处理标识.
+func buildAllow(methods map[string]struct{}, i int) string {
+	_, hasGet := methods[http.MethodGet]
+	_, hasHead := methods[http.MethodHead]
+	_, hasOptions := methods[http.MethodOptions]
+	allows := make([]string, 0, len(methods)+2)
+	for key := range methods {
+		allows = append(allows, strings.ToUpper(key))
+	}
+	if i&1 != 0 && hasGet && !hasHead {
+		allows = append(allows, http.MethodHead)
+	}
+	if i&2 != 0 && !hasOptions {
+		allows = append(allows, http.MethodOptions)
+	}
+	sort.Strings(allows)
+	return strings.Join(allows, ", ")
+}
+
+// allowIndex 返回自动处理HEAD、OPTIONS请求的标识.
+func (a *FastRouter) allowIndex() int {
+	i := 0
+	if a.HandleHEAD {
+		i |= 1
+	}
+	if a.HandleOPTIONS {
+		i |= 2
+	}
+	return i
+}
+
+// allowed 返回其他请求方法能匹配请求路径时的Allow响应头，没有匹配时返回空字符串.
+// 各请求方法匹配到同一个路径模式时直接使用注册时生成的响应头，
+// 多个路径模式重叠（如 "/users/:id" 与 "/users/:id<int>"）时合并各请求方法的匹配结果.
+func (a *FastRouter) allowed(t *routeTable, urlPath []byte, method string) string {
+	var set *methodSet
+	n, shared := 0, true
+	for _, m := range t.methods {
+		if m == method {
+			continue
+		}
+		if r := t.trees[m].lookup(urlPath); r != nil {
+			n++
+			if set == nil {
+				set = r.allowMethods
+			} else if set != r.allowMethods {
+				shared = false
+			}
+		}
+	}
+	if n == 0 {
+		return ""
+	}
+	if shared && n == len(set.methods) {
+		return set.allows[a.allowIndex()]
+	}
+	methods := map[string]struct{}{}
+	for _, m := range t.methods {
+		if m != method && t.trees[m].lookup(urlPath) != nil {
+			methods[m] = struct{}{}
+		}
+	}
+	return buildAllow(methods, a.allowIndex())
 }
 
 const (
@@ -43,214 +179,259 @@ const (
 	PathMaxSize      = 8182
 )
 
-// splitPath 分割URL路径，最大分割100次,支持基本的路径分割清理，不支持去除dot符号.
+var strSlash = []byte(URLSep)
+
+// splitPath 分割URL路径，最大分割100次,支持基本的路径分割清理，不去除dot符号，请求路径由 CleanPath 规范化.
 func splitPath(s string) []string {
-	n := SplitPathMAXSize
-	sep := URLSep
 	if len(s) == 0 {
 		return []string{URLSep}
 	}
 	if s[0] != '/' {
-		s = sep + s
+		s = URLSep + s
 	}
-	a := make([]string, n)
-	n--
-	i := 0
-	for i < n {
-		m := strings.Index(s, sep)
-		if m < 0 {
+	a := make([]string, 0, strings.Count(s, URLSep))
+	path := []byte(s)
+	for len(path) > 0 {
+		if len(a) == SplitPathMAXSize-1 {
+			a[len(a)-1] += string(path)
 			break
 		}
-		if m != 0 && i > 0 {
-			a[i-1] += s[:m]
-		}
-		if i > 0 && a[i-1] == sep {
-			s = s[m+len(sep):]
+		var seg []byte
+		seg, path = nextSegment(path)
+		a = append(a, string(seg))
+	}
+	return a
+}
 
-			continue
-		}
-		a[i] = sep
-		s = s[m+len(sep):]
+// nextSegment 返回以"/"开头的路径中的第一个片段及剩余路径，片段保留前导"/"，连续的"/"视为一个，
+// 与 splitPath 的分割结果一致，但不会产生内存分配.
+func nextSegment(path []byte) (seg, rest []byte) {
+	i := 0
+	for i+1 < len(path) && path[i+1] == '/' {
 		i++
 	}
-	if i > 0 && len(s) > 0 {
-		a[i-1] += s
+	j := i + 1
+	for j < len(path) && path[j] != '/' {
+		j++
 	}
-	return a[:i]
+	return path[i:j], path[j:]
 }
 
-func (a *FastRouter) serve(ctx *fasthttp.RequestCtx, v *route, method, urlPath string) (bool, bool) {
-	deepPath := splitPath(urlPath)
-	var m, p bool
-
-	p = len(v.deepPath) == len(deepPath) &&
-		((v.deepPath[len(v.deepPath)-1] == URLSep && deepPath[len(deepPath)-1] == URLSep) ||
-			(v.deepPath[len(v.deepPath)-1] != URLSep && deepPath[len(deepPath)-1] != URLSep))
-	if v.isPrefixHandler && len(v.deepPath) <= len(deepPath) {
-		p = true
-	}
-	m = v.method == method
-	var allows []string
-	if len(v.allowMethods) != 0 {
-		for key := range v.allowMethods {
-			allows = append(allows, strings.ToUpper(key))
-		}
-		_, ok := v.allowMethods["OPTIONS"]
-		if !ok {
-			allows = append(allows, "OPTIONS")
-		}
-		ctx.Response.Header.Set("Allow", strings.Join(allows, ","))
-	}
-
-	if !(m && p) {
-		return m, p
+func (a *FastRouter) serve(ctx *fasthttp.RequestCtx, t *routeTable, v *route, urlPath []byte) {
+	if len(v.vars) > 0 {
+		a.setRouteVars(ctx, v, urlPath)
 	}
-	for key, index := range v.varsN {
-		ctx.SetUserValue(key, deepPath[index][1:])
+	if ctx.IsOptions() {
+		ctx.SetUserValue(routePathKey, urlPath)
 	}
-	for j := range a.preHandlers {
-		if !a.preHandlers[j](ctx) {
-			return true, true
+	for j := range t.preHandlers {
+		if !t.preHandlers[j](ctx) {
+			return
 		}
 	}
 	for j := range v.preHandlers {
 		if !v.preHandlers[j](ctx) {
-			return true, true
+			return
 		}
 	}
+	if v.mount != nil {
+		serveMount(ctx, v, urlPath)
+		return
+	}
 	v.handler(ctx)
-	return m, p
+}
+
+// setRouteVars 按路由变量所在的片段位置，从请求路径中取值并解码后写入 Params，
+// SetUserValues 为true时同时写入 UserValue.
+func (a *FastRouter) setRouteVars(ctx *fasthttp.RequestCtx, v *route, urlPath []byte) {
+	urlPath = rawPath(ctx, urlPath)
+	var seg []byte
+	j := 0
+	for i := 0; len(urlPath) > 0 && j < len(v.vars); i++ {
+		if v.vars[j].catchAll && v.vars[j].index == i {
+			a.setRouteVar(ctx, v.vars[j].name, bytes.TrimLeft(urlPath, URLSep))
+			return
+		}
+		seg, urlPath = nextSegment(urlPath)
+		if v.vars[j].index == i {
+			a.setRouteVar(ctx, v.vars[j].name, seg[1:])
+			j++
+		}
+	}
+}
+
+func (a *FastRouter) setRouteVar(ctx *fasthttp.RequestCtx, name string, raw []byte) {
+	addParam(ctx, a.SetUserValues, name, raw, true)
 }
 
 func (a *FastRouter) genRoute(method, urlPath string, isPrefixHandler bool,
-	handler fasthttp.RequestHandler, preHandler ...PreHandler) route {
+	handler fasthttp.RequestHandler, preHandler ...PreHandler) (route, error) {
 	deepPath := splitPath(urlPath)
-	varN := map[string]int{}
-	var prefix string
+	pattern := append([]string(nil), deepPath...)
+	if isPrefixHandler {
+		pattern = append(pattern, "*")
+	}
+	var vars []routeVar
 	for i := range deepPath {
-		if deepPath[i] != "/" {
-			if deepPath[i][1] == ':' {
-				key := deepPath[i][2:]
-				if v, ok := varN[key]; ok && v != 0 {
-					panic(fmt.Sprintf("路由变量变量名重复：%s %s", urlPath, deepPath[i]))
-				}
-				varN[key] = i
+		catchAll := isCatchAllSegment(deepPath[i])
+		if !catchAll && !isParamSegment(deepPath[i]) {
+			continue
+		}
+		key, expr := deepPath[i][2:], ""
+		if !catchAll {
+			var err error
+			if key, expr, err = parseParam(deepPath[i]); err != nil {
+				return route{}, fmt.Errorf("%w in %s", err, urlPath)
 			}
 		}
-		if len(varN) == 0 {
-			prefix += deepPath[i]
+		if catchAll && (i != len(deepPath)-1 || isPrefixHandler) {
+			return route{}, fmt.Errorf("%w: catch-all '%s' must be the last segment of a non-prefix route: %s",
+				ErrInvalidPath, deepPath[i], urlPath)
+		}
+		if key == "" {
+			return route{}, fmt.Errorf("%w: route variable name cannot be empty: %s", ErrInvalidPath, urlPath)
+		}
+		for j := range vars {
+			if vars[j].name == key {
+				return route{}, fmt.Errorf("%w %s: %s", ErrDuplicateParam, key, urlPath)
+			}
+		}
+		check, err := a.constraint(expr)
+		if err != nil {
+			return route{}, fmt.Errorf("%w in %s", err, urlPath)
+		}
+		vars = append(vars, routeVar{index: i, name: key, expr: expr, check: check, catchAll: catchAll})
+		pattern[i] = "/:<" + expr + ">"
+		if catchAll {
+			pattern[i] = "/*"
 		}
-	}
-	if len(prefix) == 0 {
-		prefix = "/"
 	}
 	return route{
 		deepPath:        deepPath,
-		varsN:           varN,
-		prefix:          prefix,
+		vars:            vars,
 		method:          method,
 		handler:         handler,
 		urlPath:         urlPath,
+		pattern:         strings.Join(pattern, ""),
 		preHandlers:     preHandler,
 		isPrefixHandler: isPrefixHandler,
-		allowMethods:    map[string]struct{}{method: {}},
-	}
+		allowMethods:    newMethodSet(method),
+	}, nil
 }
 
 func (a *FastRouter) handle(method string, urlPath string, isPrefixHandler bool,
-	handler fasthttp.RequestHandler, preHandler ...PreHandler) {
+	handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	r, err := a.tryHandle(method, urlPath, isPrefixHandler, handler, preHandler...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (a *FastRouter) tryHandle(method string, urlPath string, isPrefixHandler bool,
+	handler fasthttp.RequestHandler, preHandler ...PreHandler) (*Route, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	if urlPath == "" {
-		panic("'URL Path' Cannot be an empty string")
+		return nil, fmt.Errorf("%w: path cannot be empty", ErrInvalidPath)
 	}
 	if urlPath[0] != '/' {
-		panic("'URL Path' must start with '/'")
+		return nil, fmt.Errorf("%w: path must start with '/': %s", ErrInvalidPath, urlPath)
 	}
-	r := a.genRoute(method, urlPath, isPrefixHandler, handler, preHandler...)
-	h, ok := a.indexRoutes[r.prefix]
-	if !ok {
-		a.indexRoutes[r.prefix] = []*route{&r}
-		a.routes = append(a.routes, &r)
-		return
+	r, err := a.genRoute(method, urlPath, isPrefixHandler, handler, preHandler...)
+	if err != nil {
+		return nil, err
 	}
-	for i := range h {
-		if len(h[i].deepPath) == len(r.deepPath) {
-			if h[i].method == r.method {
-				panic(fmt.Sprintf("route already exist : %s %s", r.urlPath, r.method))
-			}
-			h[i].allowMethods[r.method] = struct{}{}
-		}
+	if err := a.tree(method).tryInsert(&r); err != nil {
+		return nil, err
 	}
-	h = append(h, &r)
-	a.indexRoutes[r.prefix] = h
 	a.routes = append(a.routes, &r)
+	a.rebuild()
+	return &Route{router: a, route: &r}, nil
+}
+
+// TryHandle 与 Handle 相同，但路径不合法或路由重复时返回错误而不是panic，
+// 错误可以通过 errors.Is 与 ErrInvalidPath、ErrDuplicateRoute、ErrDuplicateParam、ErrInvalidConstraint 比较.
+func (a *FastRouter) TryHandle(method string, urlPath string, handler fasthttp.RequestHandler,
+	preHandler ...PreHandler) (*Route, error) {
+	return a.tryHandle(method, urlPath, false, handler, preHandler...)
+}
+
+// TryPrefixHandler 与 PrefixHandler 相同，但路径不合法或路由重复时返回错误而不是panic.
+func (a *FastRouter) TryPrefixHandler(method string, prefixPath string, handler fasthttp.RequestHandler,
+	preHandler ...PreHandler) (*Route, error) {
+	return a.tryHandle(method, prefixPath, true, handler, preHandler...)
 }
 
 func (a *FastRouter) PrefixHandler(method string, prefixPath string,
-	handler fasthttp.RequestHandler, preHandler ...PreHandler) {
-	a.handle(method, prefixPath, true, handler, preHandler...)
+	handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return a.handle(method, prefixPath, true, handler, preHandler...)
 }
 
-func (a *FastRouter) Handle(method string, urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) {
-	a.handle(method, urlPath, false, handler, preHandler...)
+func (a *FastRouter) Handle(method string, urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return a.handle(method, urlPath, false, handler, preHandler...)
 }
 
-func (a *FastRouter) Post(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) {
-	a.handle(http.MethodPost, urlPath, false, handler, preHandler...)
+func (a *FastRouter) Post(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return a.handle(http.MethodPost, urlPath, false, handler, preHandler...)
 }
 
-func (a *FastRouter) Get(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) {
-	a.handle(http.MethodGet, urlPath, false, handler, preHandler...)
+func (a *FastRouter) Get(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return a.handle(http.MethodGet, urlPath, false, handler, preHandler...)
 }
 
-func (a *FastRouter) Patch(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) {
-	a.handle(http.MethodPatch, urlPath, false, handler, preHandler...)
+func (a *FastRouter) Patch(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return a.handle(http.MethodPatch, urlPath, false, handler, preHandler...)
 }
 
-func (a *FastRouter) Put(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) {
-	a.handle(http.MethodPut, urlPath, false, handler, preHandler...)
+func (a *FastRouter) Put(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return a.handle(http.MethodPut, urlPath, false, handler, preHandler...)
 }
 
-func (a *FastRouter) Head(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) {
-	a.handle(http.MethodHead, urlPath, false, handler, preHandler...)
+func (a *FastRouter) Head(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return a.handle(http.MethodHead, urlPath, false, handler, preHandler...)
 }
 
-func (a *FastRouter) Options(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) {
-	a.handle(http.MethodOptions, urlPath, false, handler, preHandler...)
+func (a *FastRouter) Options(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return a.handle(http.MethodOptions, urlPath, false, handler, preHandler...)
 }
 
-func (a *FastRouter) Delete(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) {
-	a.handle(http.MethodDelete, urlPath, false, handler, preHandler...)
+func (a *FastRouter) Delete(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return a.handle(http.MethodDelete, urlPath, false, handler, preHandler...)
 }
 
-func (a *FastRouter) Connect(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) {
-	a.handle(http.MethodConnect, urlPath, false, handler, preHandler...)
+func (a *FastRouter) Connect(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return a.handle(http.MethodConnect, urlPath, false, handler, preHandler...)
 }
 
-func (a *FastRouter) Trace(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) {
-	a.handle(http.MethodTrace, urlPath, false, handler, preHandler...)
+func (a *FastRouter) Trace(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return a.handle(http.MethodTrace, urlPath, false, handler, preHandler...)
 }
 
-func (a *FastRouter) Any(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) {
-	a.handle(http.MethodGet, urlPath, false, handler, preHandler...)
-	a.handle(http.MethodPost, urlPath, false, handler, preHandler...)
-	a.handle(http.MethodPut, urlPath, false, handler, preHandler...)
-	a.handle(http.MethodPatch, urlPath, false, handler, preHandler...)
-	a.handle(http.MethodHead, urlPath, false, handler, preHandler...)
-	a.handle(http.MethodOptions, urlPath, false, handler, preHandler...)
-	a.handle(http.MethodDelete, urlPath, false, handler, preHandler...)
-	a.handle(http.MethodConnect, urlPath, false, handler, preHandler...)
-	a.handle(http.MethodTrace, urlPath, false, handler, preHandler...)
+// Any 为全部请求方法注册路由，返回的句柄的 Use、After 作用于全部请求方法的路由.
+func (a *FastRouter) Any(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	r := a.handle(http.MethodGet, urlPath, false, handler, preHandler...)
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodHead,
+		http.MethodOptions, http.MethodDelete, http.MethodConnect, http.MethodTrace} {
+		r.others = append(r.others, a.handle(method, urlPath, false, handler, preHandler...).route)
+	}
+	return r
+}
+
+func (a *FastRouter) Static(prefixPath string, fileRootPath string) *Route {
+	return a.static(prefixPath, fileRootPath)
 }
 
-func (a *FastRouter) Static(prefixPath string, fileRootPath string) {
+func (a *FastRouter) static(prefixPath string, fileRootPath string, preHandler ...PreHandler) *Route {
 	fs := &fasthttp.FS{
 		Root:               fileRootPath,
 		GenerateIndexPages: true,
 		PathRewrite: func(ctx *fasthttp.RequestCtx) []byte {
 			// 由于默认的 今天文件会出现url重定向的问题，于是重写了静态文件路径。
 			path := ctx.Path()
+			if mountPrefix, ok := ctx.UserValue(MountPrefixKey).(string); ok && len(path) >= len(mountPrefix) {
+				path = path[len(mountPrefix):]
+			}
 			hasTrailingSlash := len(path) > 0 && path[len(path)-1] == '/'
 			prefixSize := len(prefixPath)
 			if len(prefixPath) > 0 && prefixPath[len(prefixPath)-1] == '/' {
@@ -269,109 +450,171 @@ func (a *FastRouter) Static(prefixPath string, fileRootPath string) {
 			ctx.NotFound()
 		},
 	}
-	a.handle("GET", prefixPath, true, fs.NewRequestHandler())
+	return a.handle("GET", prefixPath, true, fs.NewRequestHandler(), preHandler...)
 }
 
 func (a *FastRouter) Handler() func(ctx *fasthttp.RequestCtx) {
+	a.publish()
 	return func(ctx *fasthttp.RequestCtx) {
-		var m, p bool
-		urlPath := string(ctx.Path())
-		if len(urlPath) > PathMaxSize {
-			ctx.SetStatusCode(fasthttp.StatusRequestURITooLong)
+		t := a.loadTable()
+		if len(t.preRouting) > 0 && !a.preRoute(ctx, t) {
 			return
 		}
-		method := string(ctx.Method())
-		defer func() {
-			if err := recover(); err != nil {
-				if a.Recover != nil {
-					a.Recover(ctx, err)
-				}
-				defaultRecover(ctx, err)
-			}
-		}()
-		// 优先匹配明确的路由
-		handlers, ok := a.indexRoutes[urlPath]
-		if ok {
-			// 明确的路由前缀都相同时，优先匹配先定义的路由。
-			for i := range handlers {
-				m, p = a.serve(ctx, handlers[i], method, urlPath)
-				if m && p {
-					return
-				}
-			}
-		}
-		var normalMaxDeep int
-		var normalDeepLen int
-		var prefixMaxDeep int
-		var prefixDeepLen int
-
-		// 没有确定前缀的路由，可能是变量路由，或前缀路由。
-		for j := range a.routes {
-			r := a.routes[j]
-			if strings.HasPrefix(urlPath, r.prefix) {
-				// 优先匹配前缀最相似的路由
-				if len(r.prefix) > prefixMaxDeep && r.isPrefixHandler {
-					prefixDeepLen = len(r.prefix)
-					prefixMaxDeep = j
-					continue
-				}
-				if len(r.prefix) > normalMaxDeep {
-					normalDeepLen = len(r.prefix)
-					normalMaxDeep = j
-				}
+		urlPath := ctx.URI().PathOriginal()
+		if len(urlPath) > PathMaxSize {
+			if !a.writeProblem(ctx, fasthttp.StatusRequestURITooLong, "", "") {
+				ctx.SetStatusCode(fasthttp.StatusRequestURITooLong)
 			}
+			runAfter(ctx, t, nil)
+			return
 		}
-		if prefixDeepLen > 0 {
-			m, p = a.serve(ctx, a.routes[prefixMaxDeep], method, urlPath)
-			if m && p {
+		if needsClean(urlPath) {
+			cleaned := cleanPath(urlPath)
+			method := string(ctx.Method())
+			if a.RedirectCleanPath && method != http.MethodConnect && !bytes.Equal(cleaned, urlPath) {
+				redirectTo(ctx, method, cleaned)
+				runAfter(ctx, t, nil)
 				return
 			}
+			urlPath = cleaned
+		}
+		decoded := decodePath(urlPath)
+		if len(decoded) != len(urlPath) {
+			ctx.SetUserValue(rawPathKey, urlPath)
+		}
+		if r := t.hostRouter(ctx, a); r != a {
+			// 主机路由只执行自身的后置处理函数，之后执行当前路由的全局后置处理函数.
+			defer runAfter(ctx, t, nil)
+			r.serveRequest(ctx, decoded)
+			return
+		}
+		a.serveRequest(ctx, decoded)
+	}
+}
+
+// serveRequest 按请求路径匹配路由并处理请求，挂载的子路由使用去除挂载前缀后的路径.
+func (a *FastRouter) serveRequest(ctx *fasthttp.RequestCtx, urlPath []byte) {
+	method := ctx.Method()
+	t := a.loadTable()
+	var matched *route
+	defer func() {
+		if p := recover(); p != nil {
+			a.recoverPanic(ctx, string(method), matched, p)
 		}
-		if normalDeepLen > 0 {
-			m, p = a.serve(ctx, a.routes[normalMaxDeep], method, urlPath)
-			if m && p {
+		runAfter(ctx, t, matched)
+	}()
+	ctx.SetUserValue(routerKey, a)
+	root, ok := t.trees[string(method)]
+	if !ok {
+		root = t.mountTree
+	}
+	if matched = root.lookup(urlPath); matched != nil {
+		a.serve(ctx, t, matched, urlPath)
+		return
+	}
+	// HEAD请求使用GET路由处理，不返回响应体.
+	if a.HandleHEAD && string(method) == http.MethodHead {
+		if get, ok := t.trees[http.MethodGet]; ok {
+			if matched = get.lookup(urlPath); matched != nil {
+				ctx.Response.SkipBody = true
+				a.serve(ctx, t, matched, urlPath)
 				return
 			}
 		}
-		if !p {
-			if a.NotFound != nil {
-				a.NotFound(ctx)
+	}
+	if a.UseOnUnmatched {
+		a.serveUnmatchedGlobal(ctx, t, urlPath)
+		return
+	}
+	a.serveUnmatched(ctx, t, string(method), urlPath)
+}
+
+// serveUnmatched 处理没有匹配路由的请求：重定向、自动响应OPTIONS请求、405或404.
+func (a *FastRouter) serveUnmatched(ctx *fasthttp.RequestCtx, t *routeTable, method string, urlPath []byte) {
+	if a.redirect(ctx, t, method, urlPath) {
+		return
+	}
+	// 其他请求方法能匹配该路径时，自动响应OPTIONS请求或返回405.
+	if allow := a.allowed(t, urlPath, method); allow != "" {
+		ctx.Response.Header.Set("Allow", allow)
+		if a.HandleOPTIONS && method == http.MethodOptions {
+			if !a.preflight(ctx, t, urlPath) {
 				return
 			}
-			ctx.NotFound()
+			if a.GlobalOPTIONS != nil {
+				a.GlobalOPTIONS(ctx)
+			} else {
+				ctx.SetStatusCode(fasthttp.StatusNoContent)
+			}
 			return
 		}
-		if !m {
-			if a.NotAllowed != nil {
-				a.NotAllowed(ctx)
-			}
-			ctx.SetStatusCode(http.StatusMethodNotAllowed)
+		if g := t.groupFor(urlPath, hasNotAllowed); g != nil {
+			g.NotAllowed(ctx)
+		} else if a.NotAllowed != nil {
+			a.NotAllowed(ctx)
+		} else if a.writeProblem(ctx, http.StatusMethodNotAllowed, "", "") {
+			return
 		}
+		ctx.SetStatusCode(http.StatusMethodNotAllowed)
+		return
+	}
+	if g := t.groupFor(urlPath, hasNotFound); g != nil {
+		g.NotFound(ctx)
+		return
+	}
+	if a.NotFound != nil {
+		a.NotFound(ctx)
+		return
+	}
+	if a.writeProblem(ctx, http.StatusNotFound, "", "") {
+		return
 	}
+	// 与 ctx.NotFound 相同，但保留已设置的响应头，如全局PreHandler添加的CORS头.
+	ctx.Response.ResetBody()
+	ctx.SetStatusCode(http.StatusNotFound)
+	ctx.SetBodyString("404 Page not found")
 }
 
 func (a *FastRouter) Routers() []string {
-	var routers []string
-	for key := range a.indexRoutes {
-		for i := range a.indexRoutes[key] {
-			handler := a.indexRoutes[key][i]
-			routers = append(routers, handler.urlPath)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	routers := make([]string, 0, len(a.routes))
+	for i := range a.routes {
+		routers = append(routers, a.routes[i].urlPath)
+	}
+	for i := range a.mounts {
+		prefix := strings.TrimSuffix(a.mounts[i].urlPath, URLSep)
+		for _, sub := range a.mounts[i].mount.Routers() {
+			routers = append(routers, prefix+sub)
 		}
 	}
 	return routers
 }
 
 func (a *FastRouter) Use(handler PreHandler) *FastRouter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	a.preHandlers = append(a.preHandlers, handler)
+	a.rebuild()
 
 	return a
 }
 
 func NewRouter() *FastRouter {
-	return &FastRouter{
-		indexRoutes: map[string][]*route{},
-		routes:      []*route{},
-		preHandlers: []PreHandler{},
-		Recover:     defaultRecover,
+	a := &FastRouter{
+		trees:         map[string]*node{},
+		mountTree:     newNode(),
+		routes:        []*route{},
+		preHandlers:   []PreHandler{},
+		constraints:   map[string]ConstraintFunc{},
+		names:         map[string]*route{},
+		hosts:         map[string]*FastRouter{},
+		HandleHEAD:    true,
+		HandleOPTIONS: true,
+		SetUserValues: true,
+	}
+	for name, match := range defaultConstraints {
+		a.constraints[name] = match
 	}
+	return a
 }
@@ -3,8 +3,10 @@ package fastrouter
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"net"
+	"reflect"
 	"testing"
 	"time"
 
@@ -249,6 +251,57 @@ func TestRouterRoot(t *testing.T) {
 	}
 }
 
+func TestRouterTryHandle(t *testing.T) {
+	handlerFunc := func(_ *fasthttp.RequestCtx) {}
+	router := NewRouter()
+	if _, err := router.TryHandle("GET", "/users/:id", handlerFunc); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if _, err := router.TryPrefixHandler("GET", "/static/", handlerFunc); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		prefix   bool
+		sentinel error
+	}{
+		{"", false, ErrInvalidPath},
+		{"users", false, ErrInvalidPath},
+		{"/users/:", false, ErrInvalidPath},
+		{"/files/*path/x", false, ErrInvalidPath},
+		{"/files/*path", true, ErrInvalidPath},
+		{"/users/:name", false, ErrDuplicateRoute},
+		{"/static/", true, ErrDuplicateRoute},
+		{"/a/:id/:id", false, ErrDuplicateParam},
+		{"/a/:id<nope>", false, ErrInvalidConstraint},
+		{"/a/:id<[>", false, ErrInvalidConstraint},
+		{"/a/:id<int", false, ErrInvalidConstraint},
+	}
+	for _, tt := range tests {
+		var err error
+		if tt.prefix {
+			_, err = router.TryPrefixHandler("GET", tt.path, handlerFunc)
+		} else {
+			_, err = router.TryHandle("GET", tt.path, handlerFunc)
+		}
+		if !errors.Is(err, tt.sentinel) {
+			t.Errorf("%q: want %v, got %v", tt.path, tt.sentinel, err)
+		}
+		if tt.prefix {
+			continue
+		}
+		// 不返回错误的注册方法使用相同的error panic.
+		recv := catchPanic(func() { router.Handle("GET", tt.path, handlerFunc) })
+		if err, ok := recv.(error); !ok || !errors.Is(err, tt.sentinel) {
+			t.Errorf("%q: want panic with %v, got %v", tt.path, tt.sentinel, recv)
+		}
+	}
+	if routes := router.Routers(); len(routes) != 2 {
+		t.Errorf("failed registrations should not add routes, got %v", routes)
+	}
+}
+
 func TestRouterChaining(t *testing.T) {
 	router1 := NewRouter()
 	router2 := NewRouter()
@@ -497,8 +550,10 @@ func TestRouterPanicHandler(t *testing.T) {
 
 	panicHandled := false
 
-	router.Recover = func(ctx *fasthttp.RequestCtx, p interface{}) {
-		panicHandled = true
+	router.Recover = func(ctx *fasthttp.RequestCtx, info *PanicInfo) {
+		panicHandled = info.Value == "oops!" && info.Route == "/user/:name" && info.Method == "PUT" &&
+			bytes.Contains(info.Stack, []byte("TestRouterPanicHandler"))
+		ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
 	}
 
 	router.Handle("PUT", "/user/:name", func(_ *fasthttp.RequestCtx) {
@@ -534,6 +589,10 @@ func TestRouterPanicHandler(t *testing.T) {
 	if !panicHandled {
 		t.Fatal("simulating failed")
 	}
+	// Recover 是唯一的panic处理，默认处理不会覆盖其响应.
+	if !bytes.HasPrefix(rw.w.Bytes(), []byte("HTTP/1.1 503")) {
+		t.Fatalf("custom Recover response was overwritten: %q", rw.w.Bytes())
+	}
 }
 
 type readWriter struct {
@@ -582,3 +641,220 @@ func catchPanic(testFunc func()) (recv interface{}) {
 	testFunc()
 	return
 }
+
+func newTestCtx(method, uri string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI(uri)
+	return ctx
+}
+
+func newBenchmarkRouter() *FastRouter {
+	handlerFunc := func(_ *fasthttp.RequestCtx) {}
+	router := NewRouter()
+	router.Get("/", handlerFunc)
+	router.Get("/user/list", handlerFunc)
+	router.Get("/user/:name", handlerFunc)
+	router.Get("/user/:name/repos/:repo", handlerFunc)
+	router.Post("/user/:name", handlerFunc)
+	router.PrefixHandler("GET", "/static/", handlerFunc)
+	return router
+}
+
+func TestRouterZeroAlloc(t *testing.T) {
+	router := newBenchmarkRouter()
+	router.SetUserValues = false
+	handler := router.Handler()
+	for _, uri := range []string{"/", "/user/list", "/static/js/app.js", "/user/gopher", "/user/gopher/repos/fastrouter"} {
+		ctx := newTestCtx("GET", uri)
+		allocs := testing.AllocsPerRun(100, func() {
+			handler(ctx)
+			releaseParams(ctx)
+		})
+		if allocs != 0 {
+			t.Errorf("GET %s: want 0 allocs, got %v", uri, allocs)
+		}
+	}
+	root := router.trees["GET"]
+	for _, uri := range []string{"/user/gopher", "/user/gopher/repos/fastrouter", "//user//gopher/"} {
+		path := []byte(uri)
+		allocs := testing.AllocsPerRun(100, func() {
+			root.lookup(path)
+		})
+		if allocs != 0 {
+			t.Errorf("lookup %s: want 0 allocs, got %v", uri, allocs)
+		}
+	}
+}
+
+func TestUserValueAfterRelease(t *testing.T) {
+	router := NewRouter()
+	var saved []interface{}
+	router.Get("/users/:name", func(ctx *fasthttp.RequestCtx) {
+		saved = append(saved, ctx.UserValue("name"))
+	})
+	handler := router.Handler()
+	for _, uri := range []string{"/users/alice", "/users/bobby", "/users/a%20b"} {
+		ctx := newTestCtx("GET", uri)
+		handler(ctx)
+		releaseParams(ctx)
+	}
+	want := []interface{}{"alice", "bobby", "a b"}
+	if !reflect.DeepEqual(saved, want) {
+		t.Errorf("saved UserValue: want %q, got %q", want, saved)
+	}
+}
+
+func BenchmarkRouterStatic(b *testing.B) {
+	handler := newBenchmarkRouter().Handler()
+	ctx := newTestCtx("GET", "/user/list")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler(ctx)
+	}
+}
+
+func BenchmarkRouterParam(b *testing.B) {
+	handler := newBenchmarkRouter().Handler()
+	ctx := newTestCtx("GET", "/user/gopher/repos/fastrouter")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler(ctx)
+		releaseParams(ctx)
+	}
+}
+
+func BenchmarkRouterParamsOnly(b *testing.B) {
+	router := newBenchmarkRouter()
+	router.SetUserValues = false
+	handler := router.Handler()
+	ctx := newTestCtx("GET", "/user/gopher/repos/fastrouter")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler(ctx)
+		releaseParams(ctx)
+	}
+}
+
+// releaseParams 模拟请求结束时 fasthttp 回收 UserValue 中的 Params.
+func releaseParams(ctx *fasthttp.RequestCtx) {
+	if h, ok := ctx.UserValue(paramsKey).(*paramsHolder); ok {
+		_ = h.Close()
+		ctx.SetUserValue(paramsKey, nil)
+	}
+}
+
+func BenchmarkRouterLookupParam(b *testing.B) {
+	root := newBenchmarkRouter().trees["GET"]
+	path := []byte("/user/gopher/repos/fastrouter")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		root.lookup(path)
+	}
+}
+
+func TestRouterAutoHeadOptions(t *testing.T) {
+	router := NewRouter()
+	var get bool
+	router.Get("/path", func(ctx *fasthttp.RequestCtx) {
+		get = true
+		ctx.SetBodyString("body")
+	})
+	router.Post("/path", func(ctx *fasthttp.RequestCtx) {})
+	router.Options("/options", func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+	h := router.Handler()
+
+	ctx := newTestCtx("HEAD", "/path")
+	h(ctx)
+	if !get || ctx.Response.StatusCode() != fasthttp.StatusOK || !ctx.Response.SkipBody {
+		t.Errorf("automatic HEAD failed: get=%v code=%d skipBody=%v", get, ctx.Response.StatusCode(), ctx.Response.SkipBody)
+	}
+
+	ctx = newTestCtx("OPTIONS", "/path")
+	h(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusNoContent {
+		t.Errorf("automatic OPTIONS failed: code=%d", ctx.Response.StatusCode())
+	}
+	if allow := string(ctx.Response.Header.Peek("Allow")); allow != "GET, HEAD, OPTIONS, POST" {
+		t.Errorf("wrong Allow header: %s", allow)
+	}
+
+	ctx = newTestCtx("OPTIONS", "/options")
+	h(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Errorf("registered OPTIONS handler not used: code=%d", ctx.Response.StatusCode())
+	}
+
+	ctx = newTestCtx("OPTIONS", "/nope")
+	h(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("OPTIONS unknown path: want 404, got %d", ctx.Response.StatusCode())
+	}
+
+	router.GlobalOPTIONS = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+		ctx.Response.Header.Set("X-Global", "1")
+	}
+	ctx = newTestCtx("OPTIONS", "/path")
+	h(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK || string(ctx.Response.Header.Peek("X-Global")) != "1" ||
+		len(ctx.Response.Header.Peek("Allow")) == 0 {
+		t.Errorf("GlobalOPTIONS not used: code=%d", ctx.Response.StatusCode())
+	}
+
+	router.HandleHEAD = false
+	router.HandleOPTIONS = false
+	for _, method := range []string{"HEAD", "OPTIONS"} {
+		ctx = newTestCtx(method, "/path")
+		h(ctx)
+		if ctx.Response.StatusCode() != fasthttp.StatusMethodNotAllowed {
+			t.Errorf("%s with automatic handling disabled: want 405, got %d", method, ctx.Response.StatusCode())
+		}
+		if allow := string(ctx.Response.Header.Peek("Allow")); allow != "GET, POST" {
+			t.Errorf("wrong Allow header: %s", allow)
+		}
+	}
+}
+
+func TestRouterAllowHeader(t *testing.T) {
+	handlerFunc := func(_ *fasthttp.RequestCtx) {}
+	router := NewRouter()
+	router.Get("/users/:id", handlerFunc)
+	router.Delete("/users/:uid", handlerFunc)
+	router.Patch("/users/:id<int>", handlerFunc)
+	router.Put("/users/:id/posts/", handlerFunc)
+	h := router.Handler()
+
+	ctx := newTestCtx("GET", "/users/1")
+	h(ctx)
+	if allow := ctx.Response.Header.Peek("Allow"); len(allow) != 0 {
+		t.Errorf("Allow header on successful response: %s", allow)
+	}
+
+	tests := []struct {
+		uri   string
+		allow string
+	}{
+		{"/users/gopher", "DELETE, GET, HEAD, OPTIONS"},
+		{"/users/1", "DELETE, GET, HEAD, OPTIONS, PATCH"},
+		{"/users/1/posts/", "OPTIONS, PUT"},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 3; i++ {
+			ctx = newTestCtx("POST", tt.uri)
+			h(ctx)
+			if ctx.Response.StatusCode() != fasthttp.StatusMethodNotAllowed {
+				t.Errorf("POST %s: want 405, got %d", tt.uri, ctx.Response.StatusCode())
+			}
+			if allow := string(ctx.Response.Header.Peek("Allow")); allow != tt.allow {
+				t.Errorf("POST %s: want Allow %q, got %q", tt.uri, tt.allow, allow)
+			}
+		}
+	}
+}
@@ -2,4 +2,7 @@ module github.com/gorpher/fastrouter
 
 go 1.16
 
-require github.com/valyala/fasthttp v1.28.0
+require (
+	github.com/valyala/fasthttp v1.28.0
+	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e
+)
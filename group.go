@@ -0,0 +1,150 @@
+package fastrouter
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Group 路由分组，组内的路由共享路径前缀、PreHandler和中间件.
+// NotFound 和 NotAllowed 只作用于路径以分组前缀开头的请求，嵌套分组时最深的分组优先.
+type Group struct {
+	router      *FastRouter
+	prefix      string
+	depth       int
+	scope       *node
+	preHandlers []PreHandler
+	middlewares []Middleware
+	NotFound    fasthttp.RequestHandler
+	NotAllowed  fasthttp.RequestHandler
+}
+
+// Group 创建路由分组，prefix 为分组的路径前缀.
+func (a *FastRouter) Group(prefix string, preHandler ...PreHandler) *Group {
+	return a.newGroup(prefix, preHandler)
+}
+
+// Group 创建嵌套的路由分组，继承当前分组的路径前缀、PreHandler和中间件.
+func (g *Group) Group(prefix string, preHandler ...PreHandler) *Group {
+	sub := g.router.newGroup(g.path(prefix), g.withPreHandlers(preHandler))
+	sub.middlewares = append([]Middleware(nil), g.middlewares...)
+	return sub
+}
+
+func (a *FastRouter) newGroup(prefix string, preHandlers []PreHandler) *Group {
+	if prefix == "" || prefix[0] != '/' {
+		panic("'Group Prefix' must start with '/'")
+	}
+	prefix = strings.TrimSuffix(prefix, URLSep)
+	scopePath := prefix
+	if scopePath == "" {
+		scopePath = URLSep
+	}
+	r, err := a.genRoute("", scopePath, true, nil)
+	if err != nil {
+		panic(err)
+	}
+	scope := newNode()
+	scope.insert(&r)
+	g := &Group{
+		router:      a,
+		prefix:      prefix,
+		depth:       strings.Count(prefix, URLSep),
+		scope:       scope,
+		preHandlers: preHandlers,
+	}
+	a.mu.Lock()
+	a.groups = append(a.groups, g)
+	a.rebuild()
+	a.mu.Unlock()
+	return g
+}
+
+// path 拼接分组前缀与组内路径.
+func (g *Group) path(urlPath string) string {
+	if urlPath == "" || urlPath[0] != '/' {
+		panic("'URL Path' must start with '/'")
+	}
+	return g.prefix + urlPath
+}
+
+// withPreHandlers 返回分组的PreHandler与路由自身PreHandler的组合，分组的PreHandler先执行.
+func (g *Group) withPreHandlers(preHandler []PreHandler) []PreHandler {
+	handlers := make([]PreHandler, 0, len(g.preHandlers)+len(preHandler))
+	handlers = append(handlers, g.preHandlers...)
+	return append(handlers, preHandler...)
+}
+
+// groupFor 返回包含请求路径且满足条件的最深分组.
+func (t *routeTable) groupFor(urlPath []byte, has func(g *Group) bool) *Group {
+	var found *Group
+	for _, g := range t.groups {
+		if has(g) && (found == nil || g.depth > found.depth) && g.scope.lookup(urlPath) != nil {
+			found = g
+		}
+	}
+	return found
+}
+
+func (g *Group) PrefixHandler(method string, prefixPath string,
+	handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return g.use(g.router.handle(method, g.path(prefixPath), true, handler, g.withPreHandlers(preHandler)...))
+}
+
+func (g *Group) Handle(method string, urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return g.use(g.router.handle(method, g.path(urlPath), false, handler, g.withPreHandlers(preHandler)...))
+}
+
+func (g *Group) Post(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return g.Handle(http.MethodPost, urlPath, handler, preHandler...)
+}
+
+func (g *Group) Get(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return g.Handle(http.MethodGet, urlPath, handler, preHandler...)
+}
+
+func (g *Group) Patch(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return g.Handle(http.MethodPatch, urlPath, handler, preHandler...)
+}
+
+func (g *Group) Put(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return g.Handle(http.MethodPut, urlPath, handler, preHandler...)
+}
+
+func (g *Group) Head(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return g.Handle(http.MethodHead, urlPath, handler, preHandler...)
+}
+
+func (g *Group) Options(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return g.Handle(http.MethodOptions, urlPath, handler, preHandler...)
+}
+
+func (g *Group) Delete(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return g.Handle(http.MethodDelete, urlPath, handler, preHandler...)
+}
+
+func (g *Group) Connect(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return g.Handle(http.MethodConnect, urlPath, handler, preHandler...)
+}
+
+func (g *Group) Trace(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return g.Handle(http.MethodTrace, urlPath, handler, preHandler...)
+}
+
+func (g *Group) Any(urlPath string, handler fasthttp.RequestHandler, preHandler ...PreHandler) *Route {
+	return g.use(g.router.Any(g.path(urlPath), handler, g.withPreHandlers(preHandler)...))
+}
+
+// Static 在分组前缀下提供静态文件服务，分组的PreHandler和中间件同样生效.
+func (g *Group) Static(prefixPath string, fileRootPath string) *Route {
+	return g.use(g.router.static(g.path(prefixPath), fileRootPath, g.preHandlers...))
+}
+
+func hasNotFound(g *Group) bool {
+	return g.NotFound != nil
+}
+
+func hasNotAllowed(g *Group) bool {
+	return g.NotAllowed != nil
+}
@@ -0,0 +1,68 @@
+package fastrouter
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestGroup(t *testing.T) {
+	router := NewRouter()
+	var calls []string
+	pre := func(name string) PreHandler {
+		return func(ctx *fasthttp.RequestCtx) bool {
+			calls = append(calls, name)
+			return true
+		}
+	}
+	handler := func(name string) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			calls = append(calls, name)
+		}
+	}
+	api := router.Group("/api/", pre("api"))
+	api.Get("/users/:id", handler("users"), pre("route"))
+	v1 := api.Group("/v1", pre("v1"))
+	v1.Post("/items", handler("items"))
+	v1.PrefixHandler("GET", "/files", handler("files"))
+	v1.NotFound = handler("v1 not found")
+	api.NotAllowed = handler("api not allowed")
+
+	tests := []struct {
+		method string
+		uri    string
+		want   []string
+	}{
+		{"GET", "/api/users/1", []string{"api", "route", "users"}},
+		{"POST", "/api/v1/items", []string{"api", "v1", "items"}},
+		{"GET", "/api/v1/files/a/b", []string{"api", "v1", "files"}},
+		{"GET", "/api/v1/nope", []string{"v1 not found"}},
+		{"GET", "/api/v1", []string{"v1 not found"}},
+		{"GET", "/api/v1/items", []string{"api not allowed"}},
+		{"GET", "/api/nope", nil},
+		{"GET", "/api/v10", nil},
+	}
+	h := router.Handler()
+	for _, tt := range tests {
+		calls = nil
+		h(newTestCtx(tt.method, tt.uri))
+		if len(calls) != len(tt.want) {
+			t.Errorf("%s %s: want %v, got %v", tt.method, tt.uri, tt.want, calls)
+			continue
+		}
+		for i := range calls {
+			if calls[i] != tt.want[i] {
+				t.Errorf("%s %s: want %v, got %v", tt.method, tt.uri, tt.want, calls)
+				break
+			}
+		}
+	}
+
+	routers := router.Routers()
+	if len(routers) != 3 || routers[0] != "/api/users/:id" || routers[1] != "/api/v1/items" {
+		t.Errorf("wrong routers: %v", routers)
+	}
+	if recv := catchPanic(func() { api.Get("users", nil) }); recv == nil {
+		t.Error("registering group path not beginning with '/' did not panic")
+	}
+}
@@ -0,0 +1,128 @@
+package fastrouter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/valyala/fasthttp"
+)
+
+// HandlerE 返回错误的请求处理函数，返回的错误交给 FastRouter.ErrorHandler 统一处理.
+type HandlerE func(ctx *fasthttp.RequestCtx) error
+
+// HTTPError 带有HTTP状态码的错误，Code 为业务错误码，Message 为返回给客户端的错误信息，
+// Err 为内部错误，不会返回给客户端.
+type HTTPError struct {
+	Status  int
+	Code    string
+	Message string
+	Err     error
+}
+
+// NewHTTPError 创建HTTPError，message 为空时使用状态码对应的默认信息.
+func NewHTTPError(status int, code, message string) *HTTPError {
+	if message == "" {
+		message = fasthttp.StatusMessage(status)
+	}
+	return &HTTPError{Status: status, Code: code, Message: message}
+}
+
+func (e *HTTPError) Error() string {
+	msg := e.Message
+	if e.Code != "" {
+		msg = e.Code + ": " + msg
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return fmt.Sprintf("%d %s", e.Status, msg)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// defaultErrorHandler 默认的错误处理，HTTPError 返回其状态码和信息，其他错误返回500且不暴露错误内容.
+func (a *FastRouter) defaultErrorHandler(ctx *fasthttp.RequestCtx, err error) {
+	status, code, msg := http.StatusInternalServerError, "", ""
+	var e *HTTPError
+	if errors.As(err, &e) {
+		code, msg = e.Code, e.Message
+		if e.Status != 0 {
+			status = e.Status
+		}
+	}
+	if a.writeProblem(ctx, status, code, msg) {
+		return
+	}
+	if msg == "" {
+		msg = fasthttp.StatusMessage(status)
+	}
+	ctx.Error(msg, status)
+}
+
+// handlerE 将 HandlerE 转换为 fasthttp.RequestHandler，处理时使用当前路由的 ErrorHandler.
+func (a *FastRouter) handlerE(handler HandlerE) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if err := handler(ctx); err != nil {
+			a.handleError(ctx, err)
+		}
+	}
+}
+
+func (a *FastRouter) handleError(ctx *fasthttp.RequestCtx, err error) {
+	if a.ErrorHandler != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	a.defaultErrorHandler(ctx, err)
+}
+
+func (a *FastRouter) HandleE(method string, urlPath string, handler HandlerE, preHandler ...PreHandler) *Route {
+	return a.handle(method, urlPath, false, a.handlerE(handler), preHandler...)
+}
+
+func (a *FastRouter) GetE(urlPath string, handler HandlerE, preHandler ...PreHandler) *Route {
+	return a.HandleE(http.MethodGet, urlPath, handler, preHandler...)
+}
+
+func (a *FastRouter) PostE(urlPath string, handler HandlerE, preHandler ...PreHandler) *Route {
+	return a.HandleE(http.MethodPost, urlPath, handler, preHandler...)
+}
+
+func (a *FastRouter) PutE(urlPath string, handler HandlerE, preHandler ...PreHandler) *Route {
+	return a.HandleE(http.MethodPut, urlPath, handler, preHandler...)
+}
+
+func (a *FastRouter) PatchE(urlPath string, handler HandlerE, preHandler ...PreHandler) *Route {
+	return a.HandleE(http.MethodPatch, urlPath, handler, preHandler...)
+}
+
+func (a *FastRouter) DeleteE(urlPath string, handler HandlerE, preHandler ...PreHandler) *Route {
+	return a.HandleE(http.MethodDelete, urlPath, handler, preHandler...)
+}
+
+func (g *Group) HandleE(method string, urlPath string, handler HandlerE, preHandler ...PreHandler) *Route {
+	return g.Handle(method, urlPath, g.router.handlerE(handler), preHandler...)
+}
+
+func (g *Group) GetE(urlPath string, handler HandlerE, preHandler ...PreHandler) *Route {
+	return g.HandleE(http.MethodGet, urlPath, handler, preHandler...)
+}
+
+func (g *Group) PostE(urlPath string, handler HandlerE, preHandler ...PreHandler) *Route {
+	return g.HandleE(http.MethodPost, urlPath, handler, preHandler...)
+}
+
+func (g *Group) PutE(urlPath string, handler HandlerE, preHandler ...PreHandler) *Route {
+	return g.HandleE(http.MethodPut, urlPath, handler, preHandler...)
+}
+
+func (g *Group) PatchE(urlPath string, handler HandlerE, preHandler ...PreHandler) *Route {
+	return g.HandleE(http.MethodPatch, urlPath, handler, preHandler...)
+}
+
+func (g *Group) DeleteE(urlPath string, handler HandlerE, preHandler ...PreHandler) *Route {
+	return g.HandleE(http.MethodDelete, urlPath, handler, preHandler...)
+}
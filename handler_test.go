@@ -0,0 +1,77 @@
+package fastrouter
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestHandlerE(t *testing.T) {
+	errInternal := errors.New("db: connection refused")
+	router := NewRouter()
+	router.GetE("/ok", func(ctx *fasthttp.RequestCtx) error {
+		ctx.SetBodyString("ok")
+		return nil
+	})
+	router.GetE("/users/:id", func(ctx *fasthttp.RequestCtx) error {
+		return NewHTTPError(fasthttp.StatusNotFound, "user_not_found", "user not found")
+	})
+	router.PostE("/users", func(ctx *fasthttp.RequestCtx) error {
+		return fmt.Errorf("create user: %w", &HTTPError{Status: fasthttp.StatusConflict, Err: errInternal})
+	})
+	api := router.Group("/api")
+	api.DeleteE("/users/:id", func(ctx *fasthttp.RequestCtx) error {
+		return errInternal
+	})
+	h := router.Handler()
+
+	type result struct {
+		method, uri string
+		code        int
+		body        string
+	}
+	check := func(tests []result) {
+		for _, tt := range tests {
+			ctx := newTestCtx(tt.method, tt.uri)
+			h(ctx)
+			if ctx.Response.StatusCode() != tt.code || string(ctx.Response.Body()) != tt.body {
+				t.Errorf("%s %s: want %d %q, got %d %q", tt.method, tt.uri, tt.code, tt.body,
+					ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+		}
+	}
+	check([]result{
+		{"GET", "/ok", 200, "ok"},
+		{"GET", "/users/1", 404, "user not found"},
+		{"POST", "/users", 409, "Conflict"},
+		{"DELETE", "/api/users/1", 500, "Internal Server Error"},
+	})
+
+	var handled []error
+	router.ErrorHandler = func(ctx *fasthttp.RequestCtx, err error) {
+		handled = append(handled, err)
+		var e *HTTPError
+		if errors.As(err, &e) {
+			ctx.SetStatusCode(e.Status)
+			ctx.SetBodyString(e.Code)
+			return
+		}
+		ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+		ctx.SetBodyString(err.Error())
+	}
+	check([]result{
+		{"GET", "/users/1", 404, "user_not_found"},
+		{"DELETE", "/api/users/1", 503, "db: connection refused"},
+	})
+	if len(handled) != 2 {
+		t.Errorf("want 2 handled errors, got %v", handled)
+	}
+	if !errors.Is(fmt.Errorf("wrap: %w", &HTTPError{Err: errInternal}), errInternal) {
+		t.Error("HTTPError should unwrap to its internal error")
+	}
+	if got := (&HTTPError{Status: 400, Code: "bad_input", Message: "bad input"}).Error(); got != "400 bad_input: bad input" {
+		t.Errorf("unexpected HTTPError message %q", got)
+	}
+}
@@ -0,0 +1,97 @@
+package fastrouter
+
+import (
+	"net/http"
+
+	"github.com/valyala/fasthttp"
+)
+
+// routePathKey OPTIONS请求和经过全局PreHandler的没有匹配路由的请求，UserValue 中保存的用于匹配的请求路径，
+// 挂载的子路由中为去除挂载前缀后的路径，供 CORS 查找该路径注册的请求方法. 其他请求不保存，避免内存分配.
+const routePathKey = "fastrouter.routePath"
+
+// PreRouting 添加路由前的处理函数，在匹配路由（包括路径规范化和主机路由选择）之前按添加顺序执行，
+// 可以用于重写请求路径、生成请求ID等. 处理函数返回false时停止处理请求，只执行全局的后置处理函数.
+// 只对 Handler 所属的路由生效.
+func (a *FastRouter) PreRouting(handler PreHandler) *FastRouter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.preRouting = append(a.preRouting, handler)
+	a.rebuild()
+	return a
+}
+
+// preRoute 依次执行路由前的处理函数，返回是否继续处理请求. 处理函数中发生的panic交给 Recover 处理.
+func (a *FastRouter) preRoute(ctx *fasthttp.RequestCtx, t *routeTable) (next bool) {
+	defer func() {
+		if p := recover(); p != nil {
+			next = false
+			a.recoverPanic(ctx, string(ctx.Method()), nil, p)
+		}
+		if !next {
+			runAfter(ctx, t, nil)
+		}
+	}()
+	ctx.SetUserValue(routerKey, a)
+	for j := range t.preRouting {
+		if !t.preRouting[j](ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+// serveUnmatchedGlobal 开启 UseOnUnmatched 时，没有匹配路由的请求先经过全局PreHandler和中间件再处理.
+func (a *FastRouter) serveUnmatchedGlobal(ctx *fasthttp.RequestCtx, t *routeTable, urlPath []byte) {
+	ctx.SetUserValue(routePathKey, urlPath)
+	for j := range t.preHandlers {
+		if !t.preHandlers[j](ctx) {
+			return
+		}
+	}
+	if t.unmatched == nil {
+		a.serveUnmatched(ctx, t, string(ctx.Method()), urlPath)
+		return
+	}
+	t.unmatched(ctx)
+}
+
+// unmatchedHandler 返回使用全局中间件包装的 serveUnmatched，没有全局中间件时返回nil.
+func (a *FastRouter) unmatchedHandler(t *routeTable) fasthttp.RequestHandler {
+	if len(a.middlewares) == 0 {
+		return nil
+	}
+	return chain(func(ctx *fasthttp.RequestCtx) {
+		urlPath, _ := ctx.UserValue(routePathKey).([]byte)
+		a.serveUnmatched(ctx, t, string(ctx.Method()), urlPath)
+	}, a.middlewares)
+}
+
+// preflight 自动响应CORS预检请求之前，执行该路径上其他请求方法的路由中由 CORS 返回的PreHandler，
+// 使路由或分组中的 CORS 能够处理预检请求，路由的其他PreHandler（如认证、限流）不会执行.
+// 优先使用 Access-Control-Request-Method 对应的路由，返回false时 CORS 已经处理了请求.
+func (a *FastRouter) preflight(ctx *fasthttp.RequestCtx, t *routeTable, urlPath []byte) bool {
+	requestMethod := ctx.Request.Header.Peek("Access-Control-Request-Method")
+	if len(requestMethod) == 0 {
+		return true
+	}
+	var v *route
+	if root, ok := t.trees[string(requestMethod)]; ok {
+		v = root.lookup(urlPath)
+	}
+	for i := 0; v == nil && i < len(t.methods); i++ {
+		if t.methods[i] != http.MethodOptions {
+			v = t.trees[t.methods[i]].lookup(urlPath)
+		}
+	}
+	if v == nil || v.mount != nil {
+		return true
+	}
+	ctx.SetUserValue(routePathKey, urlPath)
+	for _, h := range v.preHandlers {
+		if isCORS(h) && !h(ctx) {
+			return false
+		}
+	}
+	return true
+}
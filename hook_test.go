@@ -0,0 +1,112 @@
+package fastrouter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestRouterUseOnUnmatched(t *testing.T) {
+	var trace []string
+	router := NewRouter()
+	router.Use(CorsHandler)
+	router.UseMiddleware(func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			trace = append(trace, "middleware")
+			next(ctx)
+		}
+	})
+	router.Get("/users", func(ctx *fasthttp.RequestCtx) {})
+	h := router.Handler()
+
+	tests := []struct {
+		method, uri string
+		code        int
+		middleware  bool
+	}{
+		{"GET", "/users", 200, true},
+		{"GET", "/nope", 404, true},
+		{"POST", "/users", 405, true},
+		// 预检请求由 CorsHandler 处理.
+		{"OPTIONS", "/users", 200, false},
+	}
+	for _, unmatched := range []bool{false, true} {
+		router.UseOnUnmatched = unmatched
+		for _, tt := range tests {
+			trace = nil
+			ctx := newTestCtx(tt.method, tt.uri)
+			ctx.Request.Header.Set("Origin", "https://example.com")
+			h(ctx)
+			matched := tt.uri == "/users" && tt.method == "GET"
+			code := tt.code
+			if !unmatched && tt.method == "OPTIONS" {
+				code = 204
+			}
+			if ctx.Response.StatusCode() != code {
+				t.Errorf("%s %s (UseOnUnmatched %v): want %d, got %d", tt.method, tt.uri, unmatched, code, ctx.Response.StatusCode())
+			}
+			cors := len(ctx.Response.Header.Peek("Access-Control-Allow-Origin")) > 0
+			if cors != (matched || unmatched) {
+				t.Errorf("%s %s (UseOnUnmatched %v): unexpected CORS headers %v", tt.method, tt.uri, unmatched, cors)
+			}
+			if ran := len(trace) > 0; ran != (tt.middleware && (matched || unmatched)) {
+				t.Errorf("%s %s (UseOnUnmatched %v): unexpected middleware run %v", tt.method, tt.uri, unmatched, ran)
+			}
+		}
+	}
+}
+
+func TestRouterPreRouting(t *testing.T) {
+	var after []int
+	router := NewRouter()
+	router.PreRouting(func(ctx *fasthttp.RequestCtx) bool {
+		ctx.Response.Header.Set("X-Request-Id", "42")
+		return true
+	}).PreRouting(func(ctx *fasthttp.RequestCtx) bool {
+		path := string(ctx.URI().PathOriginal())
+		switch {
+		case strings.HasPrefix(path, "/old/"):
+			ctx.URI().SetPath("/new/" + path[len("/old/"):])
+		case path == "/blocked":
+			ctx.SetStatusCode(fasthttp.StatusForbidden)
+			return false
+		case path == "/panic":
+			panic("boom")
+		}
+		return true
+	})
+	router.After(func(ctx *fasthttp.RequestCtx) {
+		after = append(after, ctx.Response.StatusCode())
+	})
+	router.Get("/new/:id", func(ctx *fasthttp.RequestCtx) {
+		id, _ := ParamsFromCtx(ctx).ByName("id")
+		ctx.SetBodyString(id)
+	})
+	h := router.Handler()
+
+	tests := []struct {
+		uri  string
+		code int
+		body string
+	}{
+		{"/old/7", 200, "7"},
+		{"/new/8", 200, "8"},
+		{"/blocked", 403, ""},
+		{"/panic", 500, "boom"},
+	}
+	for _, tt := range tests {
+		after = nil
+		ctx := newTestCtx("GET", tt.uri)
+		h(ctx)
+		if ctx.Response.StatusCode() != tt.code || string(ctx.Response.Body()) != tt.body {
+			t.Errorf("GET %s: want %d %q, got %d %q", tt.uri, tt.code, tt.body, ctx.Response.StatusCode(), ctx.Response.Body())
+		}
+		if tt.uri != "/panic" && string(ctx.Response.Header.Peek("X-Request-Id")) != "42" {
+			t.Errorf("GET %s: want X-Request-Id header", tt.uri)
+		}
+		if len(after) != 1 || after[0] != tt.code {
+			t.Errorf("GET %s: want after-handler once with %d, got %v", tt.uri, tt.code, after)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package fastrouter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// hostPattern 带变量的主机名模式，如 ":tenant.example.com".
+type hostPattern struct {
+	pattern string
+	labels  [][]byte // 按"."分割的小写label，变量label为nil
+	vars    []routeVar
+	router  *FastRouter
+}
+
+// Host 返回主机名对应的路由表，请求的Host头（忽略端口和大小写）匹配时使用该路由表，
+// 没有匹配的主机时使用当前路由表. pattern 可以是确定的主机名，也可以包含变量label，
+// 如 ":tenant.example.com"，变量值通过 ctx.UserValue("tenant") 获取，变量同样支持约束.
+// 主机路由表是独立的路由，拥有自己的PreHandler、NotFound、NotAllowed和Recover.
+func (a *FastRouter) Host(pattern string) *FastRouter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	pattern = strings.TrimSuffix(pattern, ".")
+	h := &hostPattern{}
+	var labels []string
+	for i, label := range strings.Split(pattern, ".") {
+		if label == "" || strings.ContainsAny(label, "/") || strings.LastIndexByte(label, ':') > 0 {
+			panic(fmt.Sprintf("invalid host pattern: %s", pattern))
+		}
+		if label[0] != ':' {
+			label = strings.ToLower(label)
+			labels = append(labels, label)
+			h.labels = append(h.labels, []byte(label))
+			continue
+		}
+		name, expr, err := parseParam("/" + label)
+		if err != nil {
+			panic(err)
+		}
+		if name == "" {
+			panic(fmt.Sprintf("host variable name cannot be empty: %s", pattern))
+		}
+		check, err := a.constraint(expr)
+		if err != nil {
+			panic(err)
+		}
+		labels = append(labels, label)
+		h.labels = append(h.labels, nil)
+		h.vars = append(h.vars, routeVar{index: i, name: name, expr: expr, check: check})
+	}
+	h.pattern = strings.Join(labels, ".")
+	if r, ok := a.hosts[h.pattern]; ok {
+		return r
+	}
+	for i := range a.hostPatterns {
+		if a.hostPatterns[i].pattern == h.pattern {
+			return a.hostPatterns[i].router
+		}
+	}
+	h.router = NewRouter()
+	for name, match := range a.constraints {
+		h.router.constraints[name] = match
+	}
+	h.router.publish()
+	if len(h.vars) == 0 {
+		a.hosts[h.pattern] = h.router
+	} else {
+		a.hostPatterns = append(a.hostPatterns, h)
+	}
+	a.rebuild()
+	return h.router
+}
+
+// hostRouter 根据请求的Host头选择路由表，没有匹配的主机时返回 a.
+func (t *routeTable) hostRouter(ctx *fasthttp.RequestCtx, a *FastRouter) *FastRouter {
+	if len(t.hosts) == 0 && len(t.hostPatterns) == 0 {
+		return a
+	}
+	host := stripHostPort(ctx.Host())
+	if hasUpper(host) {
+		host = bytes.ToLower(host)
+	}
+	if r, ok := t.hosts[string(host)]; ok {
+		return r
+	}
+	for _, h := range t.hostPatterns {
+		if h.match(host) {
+			h.setVars(ctx, host, a.SetUserValues)
+			return h.router
+		}
+	}
+	return a
+}
+
+func (h *hostPattern) match(host []byte) bool {
+	for i, label := range h.labels {
+		if len(host) == 0 {
+			return false
+		}
+		var value []byte
+		value, host = nextLabel(host)
+		if label == nil {
+			v := h.vars[h.varIndex(i)]
+			if len(value) == 0 || (v.check != nil && !v.check(value)) {
+				return false
+			}
+			continue
+		}
+		if !bytes.Equal(label, value) {
+			return false
+		}
+	}
+	return len(host) == 0
+}
+
+func (h *hostPattern) varIndex(label int) int {
+	for i := range h.vars {
+		if h.vars[i].index == label {
+			return i
+		}
+	}
+	return -1
+}
+
+func (h *hostPattern) setVars(ctx *fasthttp.RequestCtx, host []byte, setUserValue bool) {
+	var value []byte
+	j := 0
+	for i := 0; len(host) > 0 && j < len(h.vars); i++ {
+		value, host = nextLabel(host)
+		if h.vars[j].index == i {
+			addParam(ctx, setUserValue, h.vars[j].name, value, false)
+			j++
+		}
+	}
+}
+
+// nextLabel 返回主机名中的第一个label及剩余部分.
+func nextLabel(host []byte) (label, rest []byte) {
+	i := bytes.IndexByte(host, '.')
+	if i < 0 {
+		return host, nil
+	}
+	return host[:i], host[i+1:]
+}
+
+// stripHostPort 去除Host头中的端口和末尾的"."，支持IPv6地址.
+func stripHostPort(host []byte) []byte {
+	if i := bytes.LastIndexByte(host, ':'); i >= 0 && bytes.LastIndexByte(host, ']') < i {
+		host = host[:i]
+	}
+	if len(host) > 0 && host[len(host)-1] == '.' {
+		host = host[:len(host)-1]
+	}
+	return host
+}
+
+func hasUpper(b []byte) bool {
+	for _, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package fastrouter
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestHost(t *testing.T) {
+	var got string
+	record := func(name string) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			got = name
+			if tenant, ok := ctx.UserValue("tenant").(string); ok {
+				got += " " + tenant
+			}
+		}
+	}
+	router := NewRouter()
+	router.Get("/", record("default"))
+	api := router.Host("API.example.com")
+	api.Get("/", record("api"))
+	tenant := router.Host(":tenant<alnum>.example.com")
+	tenant.Get("/", record("tenant"))
+	tenant.NotFound = record("tenant not found")
+	if router.Host("api.example.com.") != api {
+		t.Fatal("registering the same host twice should return the same router")
+	}
+
+	tests := map[string]string{
+		"api.example.com":      "api",
+		"Api.Example.com:8080": "api",
+		"acme.example.com":     "tenant acme",
+		"acme.example.com.":    "tenant acme",
+		"a-b.example.com":      "default",
+		"x.y.example.com":      "default",
+		"example.com":          "default",
+		"localhost:8080":       "default",
+		"[::1]:8080":           "default",
+	}
+	h := router.Handler()
+	for host, want := range tests {
+		got = ""
+		ctx := newTestCtx("GET", "/")
+		ctx.Request.Header.SetHost(host)
+		h(ctx)
+		if got != want {
+			t.Errorf("Host %s: want %q, got %q", host, want, got)
+		}
+	}
+
+	got = ""
+	ctx := newTestCtx("GET", "/nope")
+	ctx.Request.Header.SetHost("acme.example.com")
+	h(ctx)
+	if got != "tenant not found acme" {
+		t.Errorf("host NotFound: got %q", got)
+	}
+
+	for _, pattern := range []string{"", "a..com", "example.com:8080", "a/b.com", ":.example.com"} {
+		if recv := catchPanic(func() { router.Host(pattern) }); recv == nil {
+			t.Errorf("registering host %q did not panic", pattern)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package fastrouter
+
+import "github.com/valyala/fasthttp"
+
+// Middleware 包装路由处理函数的中间件，可以在处理函数前后执行代码，如计时、修改响应、defer等.
+// 中间件在生成路由表时组合，处理请求时不会重新组合调用链.
+type Middleware func(next fasthttp.RequestHandler) fasthttp.RequestHandler
+
+// chain 按顺序组合中间件，第一个中间件在最外层.
+func chain(handler fasthttp.RequestHandler, middlewares []Middleware) fasthttp.RequestHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// UseMiddleware 添加全局中间件，作用于所有路由（包括之前注册的路由）的处理函数.
+// 中间件在PreHandler全部通过后执行，全局中间件在分组和路由的中间件外层，挂载的子路由使用自身的中间件.
+func (a *FastRouter) UseMiddleware(middlewares ...Middleware) *FastRouter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.middlewares = append(a.middlewares, middlewares...)
+	a.rebuild()
+	return a
+}
+
+// UseMiddleware 添加分组中间件，作用于之后在分组及其嵌套分组中注册的路由.
+func (g *Group) UseMiddleware(middlewares ...Middleware) *Group {
+	g.middlewares = append(g.middlewares, middlewares...)
+	return g
+}
+
+// Use 为路由添加中间件，中间件在生成路由表时按全局、分组、路由的顺序由外到内组合，
+// 同一层级中先添加的中间件在外层. Any 返回的句柄作用于全部请求方法的路由.
+func (r *Route) Use(middlewares ...Middleware) *Route {
+	a := r.router
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, v := range r.routes() {
+		v.middlewares = append(v.middlewares, middlewares...)
+	}
+	a.rebuild()
+	return r
+}
+
+// use 为分组中注册的路由添加分组中间件.
+func (g *Group) use(r *Route) *Route {
+	if len(g.middlewares) == 0 {
+		return r
+	}
+	return r.Use(g.middlewares...)
+}
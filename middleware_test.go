@@ -0,0 +1,94 @@
+package fastrouter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestMiddleware(t *testing.T) {
+	var trace []string
+	mw := func(name string) Middleware {
+		return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+			return func(ctx *fasthttp.RequestCtx) {
+				trace = append(trace, name+">")
+				next(ctx)
+				trace = append(trace, "<"+name)
+				ctx.Response.Header.Add("X-Middleware", name)
+			}
+		}
+	}
+	pre := func(ctx *fasthttp.RequestCtx) bool {
+		trace = append(trace, "pre")
+		return !ctx.QueryArgs().Has("deny")
+	}
+	handler := func(ctx *fasthttp.RequestCtx) {
+		trace = append(trace, "handler")
+	}
+
+	router := NewRouter()
+	router.Get("/users", handler, pre).Use(mw("route"))
+	api := router.Group("/api").UseMiddleware(mw("group"))
+	v1 := api.Group("/v1")
+	v1.Get("/items", handler)
+	api.UseMiddleware(mw("late"))
+	api.Get("/late", handler)
+	api.Get("/chain", handler).Use(mw("r1")).Use(mw("r2"))
+	// 全局中间件同样作用于之前注册的路由.
+	router.UseMiddleware(mw("global"))
+	h := router.Handler()
+
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"/users", "pre global> route> handler <route <global"},
+		{"/users?deny", "pre"},
+		{"/api/v1/items", "global> group> handler <group <global"},
+		{"/api/late", "global> group> late> handler <late <group <global"},
+		{"/api/chain", "global> group> late> r1> r2> handler <r2 <r1 <late <group <global"},
+	}
+	for _, tt := range tests {
+		trace = nil
+		ctx := newTestCtx("GET", tt.uri)
+		h(ctx)
+		if got := strings.Join(trace, " "); got != tt.want {
+			t.Errorf("GET %s: want %q, got %q", tt.uri, tt.want, got)
+		}
+	}
+
+	// 中间件可以在处理函数之后修改响应.
+	ctx := newTestCtx("GET", "/users")
+	h(ctx)
+	var headers []string
+	ctx.Response.Header.VisitAll(func(key, value []byte) {
+		if string(key) == "X-Middleware" {
+			headers = append(headers, string(value))
+		}
+	})
+	if strings.Join(headers, ",") != "route,global" {
+		t.Errorf("want X-Middleware route,global, got %v", headers)
+	}
+}
+
+func TestRouteUseAny(t *testing.T) {
+	var wrapped, after int
+	router := NewRouter()
+	router.Any("/any", func(ctx *fasthttp.RequestCtx) {}).Use(func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			wrapped++
+			next(ctx)
+		}
+	}).After(func(ctx *fasthttp.RequestCtx) {
+		after++
+	})
+	h := router.Handler()
+	methods := []string{"GET", "POST", "PUT", "PATCH", "HEAD", "OPTIONS", "DELETE", "CONNECT", "TRACE"}
+	for _, method := range methods {
+		h(newTestCtx(method, "/any"))
+	}
+	if wrapped != len(methods) || after != len(methods) {
+		t.Errorf("want middleware and after-handler for all %d methods, got %d and %d", len(methods), wrapped, after)
+	}
+}
@@ -0,0 +1,59 @@
+package fastrouter
+
+import (
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// MountPrefixKey 挂载的子路由处理请求时，UserValue 中保存的已去除的路径前缀.
+const MountPrefixKey = "fastrouter.mountPrefix"
+
+// Mount 将子路由挂载到路径前缀下，所有请求方法以该前缀开头的请求都交给子路由处理.
+// 子路由使用去除前缀后的路径匹配，并保留自身的PreHandler、NotFound、NotAllowed和Recover，
+// 当前路由的全局PreHandler在子路由之前执行.
+func (a *FastRouter) Mount(prefix string, sub *FastRouter) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if prefix == "" || prefix[0] != '/' {
+		panic("'Mount Prefix' must start with '/'")
+	}
+	if sub == nil || sub == a {
+		panic("invalid mounted router")
+	}
+	prefix = strings.TrimSuffix(prefix, URLSep)
+	mountPath := prefix
+	if mountPath == "" {
+		mountPath = URLSep
+	}
+	r, err := a.genRoute("*", mountPath, true, nil)
+	if err != nil {
+		panic(err)
+	}
+	sub.publish()
+	r.mount = sub
+	r.mountDepth = strings.Count(prefix, URLSep)
+	a.mountTree.insert(&r)
+	for _, root := range a.trees {
+		root.insert(&r)
+	}
+	a.mounts = append(a.mounts, &r)
+	a.rebuild()
+}
+
+// serveMount 去除挂载前缀后交给子路由处理.
+func serveMount(ctx *fasthttp.RequestCtx, v *route, urlPath []byte) {
+	rest := urlPath
+	for i := 0; i < v.mountDepth && len(rest) > 0; i++ {
+		_, rest = nextSegment(rest)
+	}
+	mountPrefix := string(urlPath[:len(urlPath)-len(rest)])
+	if parent, ok := ctx.UserValue(MountPrefixKey).(string); ok {
+		mountPrefix = parent + mountPrefix
+	}
+	ctx.SetUserValue(MountPrefixKey, mountPrefix)
+	if len(rest) == 0 {
+		rest = strSlash
+	}
+	v.mount.serveRequest(ctx, rest)
+}
@@ -0,0 +1,78 @@
+package fastrouter
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestMount(t *testing.T) {
+	var got string
+	record := func(name string) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			got = name
+		}
+	}
+
+	users := NewRouter()
+	users.Get("/", record("users.index"))
+	users.Get("/:id", func(ctx *fasthttp.RequestCtx) {
+		got = "users.show " + ctx.UserValue("id").(string) + " " + ctx.UserValue(MountPrefixKey).(string)
+	})
+	users.Handle("PROPFIND", "/:id", record("users.propfind"))
+	users.Get("/panic", func(ctx *fasthttp.RequestCtx) {
+		panic("oops")
+	})
+	users.NotFound = record("users.notfound")
+	users.Recover = func(ctx *fasthttp.RequestCtx, info *PanicInfo) {
+		got = "users.recover"
+	}
+	users.Use(func(ctx *fasthttp.RequestCtx) bool {
+		return string(ctx.Request.Header.Peek("X-Deny")) == ""
+	})
+
+	router := NewRouter()
+	router.Get("/tenants/t1/users/me", record("me"))
+	router.Mount("/tenants/:tenant/users/", users)
+	router.Get("/", record("index"))
+
+	tests := []struct {
+		method string
+		uri    string
+		want   string
+	}{
+		{"GET", "/", "index"},
+		{"GET", "/tenants/t1/users", "users.index"},
+		{"GET", "/tenants/t1/users/", "users.index"},
+		{"GET", "/tenants/t1/users/me", "me"},
+		{"GET", "/tenants/t1/users/42", "users.show 42 /tenants/t1/users"},
+		{"PROPFIND", "/tenants/t1/users/42", "users.propfind"},
+		{"GET", "/tenants/t1/users/42/x", "users.notfound"},
+		{"GET", "/tenants/t1/users/panic", "users.recover"},
+		{"GET", "/tenants/t1/nope", ""},
+	}
+	h := router.Handler()
+	for _, tt := range tests {
+		got = ""
+		ctx := newTestCtx(tt.method, tt.uri)
+		h(ctx)
+		if got != tt.want {
+			t.Errorf("%s %s: want %q, got %q", tt.method, tt.uri, tt.want, got)
+		}
+		if tt.uri == "/tenants/t1/users/42" && ctx.UserValue("tenant") != "t1" {
+			t.Errorf("wrong mount prefix variable: %v", ctx.UserValue("tenant"))
+		}
+	}
+
+	routers := router.Routers()
+	want := []string{"/tenants/t1/users/me", "/", "/tenants/:tenant/users/",
+		"/tenants/:tenant/users/:id", "/tenants/:tenant/users/:id", "/tenants/:tenant/users/panic"}
+	if len(routers) != len(want) {
+		t.Fatalf("wrong routers: %v", routers)
+	}
+	for i := range want {
+		if routers[i] != want[i] {
+			t.Fatalf("wrong routers: %v", routers)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package fastrouter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"unsafe"
+
+	"github.com/valyala/fasthttp"
+)
+
+const paramsKey = "fastrouter.params"
+
+// ErrParamNotFound 请求中不存在该路由变量.
+var ErrParamNotFound = errors.New("fastrouter: parameter not found")
+
+// Param 路由变量.
+type Param struct {
+	Key   string
+	Value string // 解码后的值
+	Raw   string // 请求路径中未解码的原始值，非保留字符的编码已由 CleanPath 解码
+}
+
+// Params 按匹配顺序排列的路由变量，包括主机名变量、挂载前缀中的变量和路由中的变量.
+// Params 及其中的变量值在请求结束后回收复用，不能在请求处理之外持有，需要持有时先复制.
+// 写入 UserValue 的变量值是独立的字符串，不受回收影响.
+type Params []Param
+
+// paramsHolder 保存在 UserValue 中的 Params，请求结束时 fasthttp 调用 Close 将其放回对象池.
+type paramsHolder struct {
+	params Params
+	buf    []byte // 变量原始值的副本，Raw 和不需要解码的 Value 直接引用其中的字节
+}
+
+var paramsPool = sync.Pool{
+	New: func() interface{} {
+		return &paramsHolder{params: make(Params, 0, 4), buf: make([]byte, 0, 64)}
+	},
+}
+
+func (h *paramsHolder) Close() error {
+	h.params = h.params[:0]
+	h.buf = h.buf[:0]
+	paramsPool.Put(h)
+	return nil
+}
+
+// ParamsFromCtx 返回请求匹配的路由变量，没有变量时返回nil.
+func ParamsFromCtx(ctx *fasthttp.RequestCtx) Params {
+	if h, ok := ctx.UserValue(paramsKey).(*paramsHolder); ok {
+		return h.params
+	}
+	return nil
+}
+
+// addParam 追加路由变量，原始值复制到 Params 的缓冲区中，decode 为true且原始值包含百分号编码时解码，
+// setUserValue 为true时同时将变量值的副本写入 UserValue. 不需要解码且不写入 UserValue 的变量不产生内存分配.
+func addParam(ctx *fasthttp.RequestCtx, setUserValue bool, key string, raw []byte, decode bool) {
+	h, ok := ctx.UserValue(paramsKey).(*paramsHolder)
+	if !ok {
+		h = paramsPool.Get().(*paramsHolder)
+		ctx.SetUserValue(paramsKey, h)
+	}
+	start := len(h.buf)
+	h.buf = append(h.buf, raw...)
+	p := Param{Key: key, Raw: b2s(h.buf[start:])}
+	p.Value = p.Raw
+	decoded := decode && bytes.IndexByte(raw, '%') >= 0
+	if decoded {
+		p.Value = unescapeSegment(raw)
+	}
+	h.params = append(h.params, p)
+	if !setUserValue {
+		return
+	}
+	if decoded {
+		// 解码后的值是新分配的字符串，不引用缓冲区.
+		ctx.SetUserValue(key, p.Value)
+	} else {
+		ctx.SetUserValue(key, string(raw))
+	}
+}
+
+// b2s 将字节切片转换为字符串而不复制，转换后不能再修改切片中的字节.
+func b2s(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+// Get 返回变量值，变量名重复时（如挂载前缀和子路由使用相同的变量名）返回最后匹配的值.
+func (ps Params) Get(name string) (Param, bool) {
+	for i := len(ps) - 1; i >= 0; i-- {
+		if ps[i].Key == name {
+			return ps[i], true
+		}
+	}
+	return Param{}, false
+}
+
+// ByName 返回解码后的变量值.
+func (ps Params) ByName(name string) (string, error) {
+	p, ok := ps.Get(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrParamNotFound, name)
+	}
+	return p.Value, nil
+}
+
+// Int 将变量值解析为int.
+func (ps Params) Int(name string) (int, error) {
+	v, err := ps.Int64(name)
+	if err != nil {
+		return 0, err
+	}
+	if int64(int(v)) != v {
+		return 0, fmt.Errorf("fastrouter: parameter %s: %w", name, strconv.ErrRange)
+	}
+	return int(v), nil
+}
+
+// Int64 将变量值解析为int64.
+func (ps Params) Int64(name string) (int64, error) {
+	value, err := ps.ByName(name)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("fastrouter: parameter %s: %w", name, err)
+	}
+	return v, nil
+}
+
+// Bool 将变量值解析为bool，支持 strconv.ParseBool 接受的写法.
+func (ps Params) Bool(name string) (bool, error) {
+	value, err := ps.ByName(name)
+	if err != nil {
+		return false, err
+	}
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("fastrouter: parameter %s: %w", name, err)
+	}
+	return v, nil
+}
+
+// UUID 将 8-4-4-4-12 格式的变量值解析为16字节的UUID.
+func (ps Params) UUID(name string) ([16]byte, error) {
+	var id [16]byte
+	value, err := ps.ByName(name)
+	if err != nil {
+		return id, err
+	}
+	if !isUUID([]byte(value)) {
+		return id, fmt.Errorf("fastrouter: parameter %s: invalid UUID %q", name, value)
+	}
+	j := 0
+	for i := 0; i < len(value); i += 2 {
+		if value[i] == '-' {
+			i++
+		}
+		id[j] = unhex(value[i])<<4 | unhex(value[i+1])
+		j++
+	}
+	return id, nil
+}
+
+// RawParam 返回路由变量在请求路径中未解码的原始值，如 "/users/:name" 匹配 "/users/a%2Fb" 时，
+// ctx.UserValue("name") 为 "a/b"，RawParam(ctx, "name") 为 "a%2Fb". 非保留字符的编码已由 CleanPath 解码.
+func RawParam(ctx *fasthttp.RequestCtx, name string) string {
+	p, _ := ParamsFromCtx(ctx).Get(name)
+	return p.Raw
+}
@@ -0,0 +1,125 @@
+package fastrouter
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestParams(t *testing.T) {
+	var ps Params
+	router := NewRouter()
+	router.Get("/users/:id/:flag/:uuid/*rest", func(ctx *fasthttp.RequestCtx) {
+		ps = append(Params(nil), ParamsFromCtx(ctx)...)
+	})
+	ctx := newTestCtx("GET", "/users/42/true/123e4567-e89b-12d3-a456-426614174000/a%2Fb/c")
+	router.Handler()(ctx)
+
+	want := Params{
+		{Key: "id", Value: "42", Raw: "42"},
+		{Key: "flag", Value: "true", Raw: "true"},
+		{Key: "uuid", Value: "123e4567-e89b-12d3-a456-426614174000", Raw: "123e4567-e89b-12d3-a456-426614174000"},
+		{Key: "rest", Value: "a/b/c", Raw: "a%2Fb/c"},
+	}
+	if len(ps) != len(want) {
+		t.Fatalf("want %v, got %v", want, ps)
+	}
+	for i := range want {
+		if ps[i] != want[i] {
+			t.Errorf("param %d: want %v, got %v", i, want[i], ps[i])
+		}
+	}
+	if ctx.UserValue("id") != "42" {
+		t.Errorf("UserValue should be set by default, got %v", ctx.UserValue("id"))
+	}
+
+	if v, err := ps.ByName("rest"); err != nil || v != "a/b/c" {
+		t.Errorf("ByName: got %q %v", v, err)
+	}
+	if _, err := ps.ByName("nope"); !errors.Is(err, ErrParamNotFound) {
+		t.Errorf("ByName: want ErrParamNotFound, got %v", err)
+	}
+	if v, err := ps.Int("id"); err != nil || v != 42 {
+		t.Errorf("Int: got %d %v", v, err)
+	}
+	if v, err := ps.Int64("id"); err != nil || v != 42 {
+		t.Errorf("Int64: got %d %v", v, err)
+	}
+	if _, err := ps.Int("flag"); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Int: want ErrSyntax, got %v", err)
+	}
+	if v, err := ps.Bool("flag"); err != nil || !v {
+		t.Errorf("Bool: got %v %v", v, err)
+	}
+	if _, err := ps.Bool("id"); err == nil {
+		t.Error("Bool: want error")
+	}
+	id, err := ps.UUID("uuid")
+	if err != nil || id != [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3,
+		0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00} {
+		t.Errorf("UUID: got %x %v", id, err)
+	}
+	if _, err := ps.UUID("id"); err == nil {
+		t.Error("UUID: want error")
+	}
+	if _, err := ps.Int64("nope"); !errors.Is(err, ErrParamNotFound) {
+		t.Errorf("Int64: want ErrParamNotFound, got %v", err)
+	}
+}
+
+func TestParamsWithoutUserValues(t *testing.T) {
+	router := NewRouter()
+	router.SetUserValues = false
+	var name string
+	var err error
+	router.Get("/users/:name", func(ctx *fasthttp.RequestCtx) {
+		name, err = ParamsFromCtx(ctx).ByName("name")
+	})
+	sub := NewRouter()
+	sub.Get("/:id", func(ctx *fasthttp.RequestCtx) {
+		ps := ParamsFromCtx(ctx)
+		if len(ps) != 2 || ps[0].Key != "tenant" || ps[1].Key != "id" {
+			t.Errorf("mounted params should keep match order, got %v", ps)
+		}
+	})
+	router.Mount("/tenants/:tenant", sub)
+	h := router.Handler()
+
+	ctx := newTestCtx("GET", "/users/gopher")
+	h(ctx)
+	if err != nil || name != "gopher" {
+		t.Errorf("want gopher, got %q %v", name, err)
+	}
+	if ctx.UserValue("name") != nil {
+		t.Errorf("UserValue should not be set, got %v", ctx.UserValue("name"))
+	}
+
+	ctx = newTestCtx("GET", "/tenants/t1/7")
+	h(ctx)
+	if ctx.Response.StatusCode() != 200 {
+		t.Errorf("want 200, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx = newTestCtx("GET", "/")
+	h(ctx)
+	if ParamsFromCtx(ctx) != nil {
+		t.Error("want nil params for routes without variables")
+	}
+}
+
+func TestParamsRelease(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/:name", func(ctx *fasthttp.RequestCtx) {})
+	ctx := newTestCtx("GET", "/users/gopher")
+	router.Handler()(ctx)
+	h, ok := ctx.UserValue(paramsKey).(*paramsHolder)
+	if !ok || len(h.params) != 1 {
+		t.Fatalf("want params holder with 1 param, got %v", ctx.UserValue(paramsKey))
+	}
+	releaseParams(ctx)
+	if len(h.params) != 0 || ParamsFromCtx(ctx) != nil {
+		t.Error("params should be reset after release")
+	}
+}
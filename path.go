@@ -0,0 +1,196 @@
+package fastrouter
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// CleanPath 按 RFC 3986 规范化URL路径：补全开头的"/"，解码非保留字符（字母、数字、"-._~"）的百分号编码，
+// 合并连续的"/"，去除"."和".."片段，保留末尾的"/"（以"."或".."结尾时同样保留）.
+// 其他百分号编码保持不变，编码的"/"（"%2F"）不会被当作路径分隔符.
+//
+//	CleanPath("/static/../admin")  // "/admin"
+//	CleanPath("//a/./b/%2e%2e/c/") // "/a/c/"
+//	CleanPath("/a/..%2Fb")         // "/a/..%2Fb"
+func CleanPath(p string) string {
+	b := []byte(p)
+	if !needsClean(b) {
+		return p
+	}
+	return string(cleanPath(b))
+}
+
+// needsClean 判断路径是否需要规范化，不需要时可以避免内存分配.
+func needsClean(p []byte) bool {
+	if len(p) == 0 || p[0] != '/' {
+		return true
+	}
+	for i := 0; i < len(p); i++ {
+		switch p[i] {
+		case '%':
+			if i+2 < len(p) && isUnreservedEscape(p[i+1], p[i+2]) {
+				return true
+			}
+		case '/':
+			if i+1 < len(p) && (p[i+1] == '/' || p[i+1] == '.' && isDotSegment(p[i+1:])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isDotSegment 判断以"."开头的剩余路径的第一个片段是否为"."或"..".
+func isDotSegment(p []byte) bool {
+	end := bytes.IndexByte(p, '/')
+	if end < 0 {
+		end = len(p)
+	}
+	return end == 1 || end == 2 && p[1] == '.'
+}
+
+func cleanPath(p []byte) []byte {
+	// 解码非保留字符，使 "%2e%2e" 等价于 "..".
+	buf := make([]byte, 0, len(p))
+	for i := 0; i < len(p); i++ {
+		if p[i] == '%' && i+2 < len(p) && isUnreservedEscape(p[i+1], p[i+2]) {
+			buf = append(buf, unhex(p[i+1])<<4|unhex(p[i+2]))
+			i += 2
+			continue
+		}
+		buf = append(buf, p[i])
+	}
+
+	out := make([]byte, 1, len(buf)+1)
+	out[0] = '/'
+	trailingSlash := len(buf) == 0 || buf[len(buf)-1] == '/'
+	for len(buf) > 0 {
+		if buf[0] == '/' {
+			buf = buf[1:]
+			continue
+		}
+		end := bytes.IndexByte(buf, '/')
+		if end < 0 {
+			end = len(buf)
+		}
+		seg := buf[:end]
+		buf = buf[end:]
+		switch string(seg) {
+		case ".":
+			trailingSlash = trailingSlash || len(buf) == 0
+		case "..":
+			trailingSlash = trailingSlash || len(buf) == 0
+			if len(out) > 1 {
+				out = out[:bytes.LastIndexByte(out[:len(out)-1], '/')+1]
+			}
+		default:
+			out = append(out, seg...)
+			out = append(out, '/')
+		}
+	}
+	if !trailingSlash && len(out) > 1 {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+// decodePath 解码路径中除 "%2F" 和 "%25" 以外的百分号编码，编码的"/"保留在路径片段内，
+// 由 unescapeSegment 在取变量值时解码.
+func decodePath(p []byte) []byte {
+	if bytes.IndexByte(p, '%') < 0 {
+		return p
+	}
+	buf := make([]byte, 0, len(p))
+	for i := 0; i < len(p); i++ {
+		if p[i] == '%' && i+2 < len(p) && isHexChar(p[i+1]) && isHexChar(p[i+2]) {
+			c := unhex(p[i+1])<<4 | unhex(p[i+2])
+			if c != '/' && c != '%' {
+				buf = append(buf, c)
+				i += 2
+				continue
+			}
+		}
+		buf = append(buf, p[i])
+	}
+	return buf
+}
+
+// unescapeSegment 解码路径片段中剩余的百分号编码.
+func unescapeSegment(seg []byte) string {
+	if bytes.IndexByte(seg, '%') < 0 {
+		return string(seg)
+	}
+	var b strings.Builder
+	b.Grow(len(seg))
+	for i := 0; i < len(seg); i++ {
+		if seg[i] == '%' && i+2 < len(seg) && isHexChar(seg[i+1]) && isHexChar(seg[i+2]) {
+			b.WriteByte(unhex(seg[i+1])<<4 | unhex(seg[i+2]))
+			i += 2
+			continue
+		}
+		b.WriteByte(seg[i])
+	}
+	return b.String()
+}
+
+// escapePath 转义路径中不能直接出现在 Location 等响应头中的字符，已有的百分号编码保持不变.
+func escapePath(p []byte) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	b.Grow(len(p))
+	for _, c := range p {
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"<>\^`+"`{|}#?", c) >= 0 {
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&15])
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func isUnreservedEscape(h, l byte) bool {
+	if !isHexChar(h) || !isHexChar(l) {
+		return false
+	}
+	c := unhex(h)<<4 | unhex(l)
+	return isAlnumChar(c) || c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+const rawPathKey = "fastrouter.rawPath"
+
+// rawPath 返回与 urlPath 对应的未解码路径. 解码不改变路径片段的划分，
+// 挂载的子路由只去除了开头的片段，因此按末尾对齐即可.
+func rawPath(ctx *fasthttp.RequestCtx, urlPath []byte) []byte {
+	raw, ok := ctx.UserValue(rawPathKey).([]byte)
+	if !ok {
+		return urlPath
+	}
+	for n := countSegments(raw) - countSegments(urlPath); n > 0; n-- {
+		_, raw = nextSegment(raw)
+	}
+	return raw
+}
+
+func countSegments(path []byte) int {
+	n := 0
+	for len(path) > 0 {
+		_, path = nextSegment(path)
+		n++
+	}
+	return n
+}
@@ -0,0 +1,171 @@
+package fastrouter
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestCleanPath(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{"", "/"},
+		{"/", "/"},
+		{"abc", "/abc"},
+		{"/abc", "/abc"},
+		{"/abc/", "/abc/"},
+		{"//abc//def//", "/abc/def/"},
+		{"/./", "/"},
+		{"/.", "/"},
+		{"/..", "/"},
+		{"/../../abc", "/abc"},
+		{"/abc/.", "/abc/"},
+		{"/abc/..", "/"},
+		{"/abc/def/..", "/abc/"},
+		{"/abc/./def", "/abc/def"},
+		{"/abc/def/../ghi", "/abc/ghi"},
+		{"/static/../admin", "/admin"},
+		{"/static/%2e%2e/admin", "/admin"},
+		{"/static/%2E%2E/%2e/admin", "/admin"},
+		{"/a/..b/.c", "/a/..b/.c"},
+		{"/%7Euser/%41", "/~user/A"},
+		{"/a/..%2Fadmin", "/a/..%2Fadmin"},
+		{"/a/%2F/b", "/a/%2F/b"},
+		{"/a%20b/%E4%B8%AD", "/a%20b/%E4%B8%AD"},
+	}
+	for _, tt := range tests {
+		if got := CleanPath(tt.path); got != tt.want {
+			t.Errorf("CleanPath(%q): want %q, got %q", tt.path, tt.want, got)
+		}
+		if got := CleanPath(tt.want); got != tt.want {
+			t.Errorf("CleanPath(%q) is not idempotent: got %q", tt.want, got)
+		}
+	}
+}
+
+func TestRouterCleanPath(t *testing.T) {
+	var denied bool
+	router := NewRouter()
+	router.PrefixHandler("GET", "/static/", func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString("static")
+	}, func(ctx *fasthttp.RequestCtx) bool {
+		denied = true
+		return true
+	})
+	router.Get("/admin", func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString("admin")
+	}, func(ctx *fasthttp.RequestCtx) bool {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+		return false
+	})
+	router.Get("/users/:name", func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(ctx.UserValue("name").(string))
+	})
+	router.Get("/中文", func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString("unicode")
+	})
+	h := router.Handler()
+
+	tests := []struct {
+		uri  string
+		code int
+		body string
+	}{
+		{"/static/../admin", 403, ""},
+		{"/static/%2e%2e/admin", 403, ""},
+		{"/static//..//admin", 403, ""},
+		{"/static/./a.css", 200, "static"},
+		{"/users/a%2Fb", 200, "a/b"},
+		{"/users/a%20b", 200, "a b"},
+		{"/users/%2e%2e", 404, ""},
+		{"/users/a%2F..", 200, "a/.."},
+		{"/%E4%B8%AD%E6%96%87", 200, "unicode"},
+	}
+	for _, tt := range tests {
+		denied = false
+		ctx := newTestCtx("GET", tt.uri)
+		h(ctx)
+		if tt.code == 403 && denied {
+			t.Errorf("%s: prefix PreHandler should not run for cleaned paths outside the prefix", tt.uri)
+		}
+		if ctx.Response.StatusCode() != tt.code {
+			t.Errorf("%s: want %d, got %d", tt.uri, tt.code, ctx.Response.StatusCode())
+		}
+		if tt.code == 200 && string(ctx.Response.Body()) != tt.body {
+			t.Errorf("%s: want body %q, got %q", tt.uri, tt.body, ctx.Response.Body())
+		}
+	}
+
+	router.RedirectCleanPath = true
+	for uri, location := range map[string]string{
+		"/static/../admin?a=1": "/admin?a=1",
+		"/users//%7Egopher":    "/users/~gopher",
+		"/users/./b/":          "/users/b/",
+	} {
+		ctx := newTestCtx("GET", uri)
+		h(ctx)
+		if ctx.Response.StatusCode() != 301 {
+			t.Errorf("%s: want 301, got %d", uri, ctx.Response.StatusCode())
+		}
+		if got := string(ctx.Response.Header.Peek("Location")); got != location {
+			t.Errorf("%s: want Location %q, got %q", uri, location, got)
+		}
+	}
+	ctx := newTestCtx("GET", "/users/a%2Fb")
+	h(ctx)
+	if ctx.Response.StatusCode() != 200 || string(ctx.Response.Body()) != "a/b" {
+		t.Errorf("clean path should not be redirected, got %d %q", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+}
+
+func TestRawParam(t *testing.T) {
+	var name, raw, file, rawFile string
+	handlerFunc := func(ctx *fasthttp.RequestCtx) {
+		name, _ = ctx.UserValue("name").(string)
+		raw = RawParam(ctx, "name")
+		file, _ = ctx.UserValue("filepath").(string)
+		rawFile = RawParam(ctx, "filepath")
+	}
+	router := NewRouter()
+	router.Get("/users/:name", handlerFunc)
+	router.Get("/users/:name/files/*filepath", handlerFunc)
+	router.Get("/id/:id<int>", handlerFunc)
+	sub := NewRouter()
+	sub.Get("/:name", handlerFunc)
+	router.Mount("/中文", sub)
+	h := router.Handler()
+
+	tests := []struct {
+		uri                      string
+		name, raw, file, rawFile string
+	}{
+		{"/users/gopher", "gopher", "gopher", "", ""},
+		{"/users/a%2Fb", "a/b", "a%2Fb", "", ""},
+		{"/users/a%20b", "a b", "a%20b", "", ""},
+		{"/users/%25", "%", "%25", "", ""},
+		{"/users/%7Egopher", "~gopher", "~gopher", "", ""},
+		{"/users/a%2Fb/files/x%2Fy/%E4%B8%AD", "a/b", "a%2Fb", "x/y/中", "x%2Fy/%E4%B8%AD"},
+		{"/%E4%B8%AD%E6%96%87/a%3Fb", "a?b", "a%3Fb", "", ""},
+	}
+	for _, tt := range tests {
+		name, raw, file, rawFile = "", "", "", ""
+		ctx := newTestCtx("GET", tt.uri)
+		h(ctx)
+		if ctx.Response.StatusCode() != 200 {
+			t.Errorf("%s: want 200, got %d", tt.uri, ctx.Response.StatusCode())
+			continue
+		}
+		if name != tt.name || raw != tt.raw || file != tt.file || rawFile != tt.rawFile {
+			t.Errorf("%s: want %q %q %q %q, got %q %q %q %q", tt.uri,
+				tt.name, tt.raw, tt.file, tt.rawFile, name, raw, file, rawFile)
+		}
+	}
+
+	ctx := newTestCtx("GET", "/id/%31%32")
+	h(ctx)
+	if ctx.Response.StatusCode() != 200 || ctx.UserValue("id") != "12" || RawParam(ctx, "id") != "12" {
+		t.Errorf("encoded digits should match int constraint, got %d %v %q",
+			ctx.Response.StatusCode(), ctx.UserValue("id"), RawParam(ctx, "id"))
+	}
+}
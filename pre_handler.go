@@ -7,6 +7,7 @@ import (
 	"github.com/valyala/fasthttp"
 )
 
+// CorsHandler 允许任意来源携带凭证的跨域请求，需要限制来源时使用 CORS.
 func CorsHandler(ctx *fasthttp.RequestCtx) bool {
 	origin := ctx.Request.Header.Peek("Origin")
 	ctx.Response.Header.Set("Access-Control-Allow-Origin", strings.Join([]string{string(origin)}, ","))
@@ -18,23 +19,17 @@ func CorsHandler(ctx *fasthttp.RequestCtx) bool {
 }
 
 // BasicAuth is the basic auth handler.
+// 只允许一个用户，需要多个用户或密码哈希时使用 BasicAuthWithConfig.
 func BasicAuth(requiredUser, requiredPassword string) PreHandler {
-	return func(ctx *fasthttp.RequestCtx) bool {
-		auth := ctx.Request.Header.Peek("Authorization")
-		if auth == nil {
-			ctx.Error(fasthttp.StatusMessage(fasthttp.StatusUnauthorized), fasthttp.StatusUnauthorized)
-			ctx.Response.Header.Set("WWW-Authenticate", "Basic realm=Restricted")
-			return false
-		}
-		user, password, hasAuth := parseBasicAuth(string(auth))
+	return BasicAuthWithConfig(BasicAuthConfig{Users: map[string]string{requiredUser: requiredPassword}})
+}
 
-		if hasAuth && user == requiredUser && password == requiredPassword {
-			return true
-		}
+// unauthorized 返回401和 WWW-Authenticate 头，开启 ProblemDetails 时输出问题详情.
+func unauthorized(ctx *fasthttp.RequestCtx, challenge string) {
+	if !routerFromCtx(ctx).writeProblem(ctx, fasthttp.StatusUnauthorized, "", "") {
 		ctx.Error(fasthttp.StatusMessage(fasthttp.StatusUnauthorized), fasthttp.StatusUnauthorized)
-		ctx.Response.Header.Set("WWW-Authenticate", "Basic realm=Restricted")
-		return false
 	}
+	ctx.Response.Header.Set("WWW-Authenticate", challenge)
 }
 
 // parseBasicAuth parses an HTTP Basic Authentication string.
@@ -0,0 +1,90 @@
+package fastrouter
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ProblemContentType RFC 7807 问题详情的响应类型.
+const ProblemContentType = "application/problem+json"
+
+// routerKey 处理请求的路由保存在 UserValue 中的键，供 BasicAuth 等 PreHandler 使用.
+const routerKey = "fastrouter.router"
+
+// Problem RFC 7807 问题详情，Code 为 HTTPError 的业务错误码，作为扩展字段输出.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code,omitempty"`
+}
+
+// routerFromCtx 返回正在处理请求的路由，挂载的子路由处理请求时返回子路由.
+func routerFromCtx(ctx *fasthttp.RequestCtx) *FastRouter {
+	a, _ := ctx.UserValue(routerKey).(*FastRouter)
+	return a
+}
+
+// writeProblem 开启 ProblemDetails 且客户端接受JSON时，将路由产生的错误输出为问题详情并返回true，
+// 否则返回false，由调用方输出文本响应. a 为nil时返回false.
+func (a *FastRouter) writeProblem(ctx *fasthttp.RequestCtx, status int, code, detail string) bool {
+	if a == nil || !a.ProblemDetails || !acceptsJSON(ctx.Request.Header.Peek("Accept")) {
+		return false
+	}
+	body, _ := json.Marshal(&Problem{
+		Type:     "about:blank",
+		Title:    fasthttp.StatusMessage(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: string(ctx.URI().PathOriginal()),
+		Code:     code,
+	})
+	ctx.Response.ResetBody()
+	ctx.SetStatusCode(status)
+	ctx.SetContentType(ProblemContentType)
+	ctx.SetBody(body)
+	return true
+}
+
+// acceptsJSON 判断 Accept 头是否接受JSON响应，没有 Accept 头时视为接受.
+func acceptsJSON(accept []byte) bool {
+	if len(bytes.TrimSpace(accept)) == 0 {
+		return true
+	}
+	for len(accept) > 0 {
+		var part []byte
+		if i := bytes.IndexByte(accept, ','); i >= 0 {
+			part, accept = accept[:i], accept[i+1:]
+		} else {
+			part, accept = accept, nil
+		}
+		mediaType, params := part, []byte(nil)
+		if i := bytes.IndexByte(part, ';'); i >= 0 {
+			mediaType, params = part[:i], part[i+1:]
+		}
+		if isZeroQuality(params) {
+			continue
+		}
+		switch string(bytes.ToLower(bytes.TrimSpace(mediaType))) {
+		case ProblemContentType, "application/json", "application/*", "*/*":
+			return true
+		}
+	}
+	return false
+}
+
+// isZeroQuality 判断媒体类型参数中是否有 q=0.
+func isZeroQuality(params []byte) bool {
+	for _, p := range bytes.Split(params, []byte(";")) {
+		p = bytes.TrimSpace(p)
+		if len(p) > 2 && (p[0] == 'q' || p[0] == 'Q') && p[1] == '=' {
+			q := bytes.TrimRight(p[2:], "0")
+			return len(q) == 0 || string(q) == "0."
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package fastrouter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestProblemDetails(t *testing.T) {
+	router := NewRouter()
+	router.ProblemDetails = true
+	router.Get("/users", func(ctx *fasthttp.RequestCtx) {})
+	router.Get("/panic", func(ctx *fasthttp.RequestCtx) {
+		panic("boom")
+	})
+	router.GetE("/error", func(ctx *fasthttp.RequestCtx) error {
+		return NewHTTPError(fasthttp.StatusConflict, "user_exists", "user already exists")
+	})
+	router.Get("/private", func(ctx *fasthttp.RequestCtx) {}, BasicAuth("user", "pass"))
+	h := router.Handler()
+
+	tests := []struct {
+		method, uri string
+		want        Problem
+	}{
+		{"GET", "/nope", Problem{Type: "about:blank", Title: "Not Found", Status: 404, Instance: "/nope"}},
+		{"POST", "/users", Problem{Type: "about:blank", Title: "Method Not Allowed", Status: 405, Instance: "/users"}},
+		{"GET", "/panic", Problem{Type: "about:blank", Title: "Internal Server Error", Status: 500, Detail: "boom", Instance: "/panic"}},
+		{"GET", "/error", Problem{Type: "about:blank", Title: "Conflict", Status: 409, Detail: "user already exists",
+			Instance: "/error", Code: "user_exists"}},
+		{"GET", "/private", Problem{Type: "about:blank", Title: "Unauthorized", Status: 401, Instance: "/private"}},
+		{"GET", "/" + strings.Repeat("a", PathMaxSize), Problem{Type: "about:blank", Title: "Request URI Too Long", Status: 414,
+			Instance: "/" + strings.Repeat("a", PathMaxSize)}},
+	}
+	for _, tt := range tests {
+		for _, accept := range []string{"", "application/problem+json", "text/html, */*;q=0.8", "application/json"} {
+			ctx := newTestCtx(tt.method, tt.uri)
+			if accept != "" {
+				ctx.Request.Header.Set("Accept", accept)
+			}
+			h(ctx)
+			if ctx.Response.StatusCode() != tt.want.Status {
+				t.Errorf("%s %s: want %d, got %d", tt.method, tt.uri, tt.want.Status, ctx.Response.StatusCode())
+			}
+			if ct := string(ctx.Response.Header.ContentType()); ct != ProblemContentType {
+				t.Errorf("%s %s (Accept %q): want %s, got %s", tt.method, tt.uri, accept, ProblemContentType, ct)
+				continue
+			}
+			var got Problem
+			if err := json.Unmarshal(ctx.Response.Body(), &got); err != nil || got != tt.want {
+				t.Errorf("%s %s: want %+v, got %s", tt.method, tt.uri, tt.want, ctx.Response.Body())
+			}
+		}
+	}
+
+	// 客户端不接受JSON时输出文本.
+	for _, accept := range []string{"text/plain", "text/html, application/json;q=0"} {
+		ctx := newTestCtx("GET", "/private")
+		ctx.Request.Header.Set("Accept", accept)
+		h(ctx)
+		if ctx.Response.StatusCode() != 401 || strings.HasPrefix(string(ctx.Response.Header.ContentType()), ProblemContentType) {
+			t.Errorf("Accept %q: want text 401, got %d %s", accept, ctx.Response.StatusCode(), ctx.Response.Header.ContentType())
+		}
+		if len(ctx.Response.Header.Peek("WWW-Authenticate")) == 0 {
+			t.Errorf("Accept %q: want WWW-Authenticate header", accept)
+		}
+	}
+
+	// 默认关闭时保持原有的文本响应.
+	router.ProblemDetails = false
+	ctx := newTestCtx("GET", "/nope")
+	h(ctx)
+	if ctx.Response.StatusCode() != 404 || strings.HasPrefix(string(ctx.Response.Header.ContentType()), ProblemContentType) {
+		t.Errorf("want text 404, got %d %s", ctx.Response.StatusCode(), ctx.Response.Header.ContentType())
+	}
+}
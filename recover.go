@@ -0,0 +1,57 @@
+package fastrouter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ErrAbortHandler 用于中止请求处理的panic值，与 http.ErrAbortHandler 相同.
+// 以该值（或包装了该值的error）panic时，路由不会调用 Recover，而是清空响应并在响应后关闭连接，
+// 不记录日志. fasthttp 不会recover处理函数中的panic，因此不会继续向上panic.
+var ErrAbortHandler = http.ErrAbortHandler
+
+// PanicInfo 处理请求时发生panic的信息.
+type PanicInfo struct {
+	Value  interface{} // recover() 返回的值
+	Stack  []byte      // 发生panic的goroutine的调用栈
+	Route  string      // 匹配的路由路径，如 "/users/:id"，挂载的子路由中为去除挂载前缀后的路径，没有匹配的路由时为空
+	Method string      // 请求方法
+}
+
+func (p *PanicInfo) String() string {
+	return fmt.Sprintf("panic serving %s %s: %v\n%s", p.Method, p.Route, p.Value, p.Stack)
+}
+
+// defaultRecover 默认的panic处理，返回500，开启 ProblemDetails 时输出问题详情.
+func defaultRecover(ctx *fasthttp.RequestCtx, info *PanicInfo) {
+	detail := fmt.Sprintf("%v", info.Value)
+	if routerFromCtx(ctx).writeProblem(ctx, http.StatusInternalServerError, "", detail) {
+		return
+	}
+	ctx.Error(detail, http.StatusInternalServerError)
+}
+
+// recoverPanic 处理请求中发生的panic，ErrAbortHandler 中止请求并关闭连接，其他值记录日志后交给 Recover 处理.
+func (a *FastRouter) recoverPanic(ctx *fasthttp.RequestCtx, method string, matched *route, p interface{}) {
+	if err, ok := p.(error); ok && errors.Is(err, ErrAbortHandler) {
+		ctx.Response.Reset()
+		ctx.SetConnectionClose()
+		return
+	}
+	info := &PanicInfo{Value: p, Stack: debug.Stack(), Method: method}
+	if matched != nil {
+		info.Route = matched.urlPath
+	}
+	if a.Logger != nil {
+		a.Logger.Printf("%s", info)
+	}
+	if a.Recover != nil {
+		a.Recover(ctx, info)
+		return
+	}
+	defaultRecover(ctx, info)
+}
@@ -0,0 +1,75 @@
+package fastrouter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+type testLogger struct {
+	bytes.Buffer
+}
+
+func (l *testLogger) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(&l.Buffer, format, args...)
+}
+
+func TestRouterRecover(t *testing.T) {
+	logger := &testLogger{}
+	router := NewRouter()
+	router.Logger = logger
+	router.Get("/users/:id", func(ctx *fasthttp.RequestCtx) {
+		panic("boom")
+	})
+	router.Get("/abort", func(ctx *fasthttp.RequestCtx) {
+		panic(fmt.Errorf("client gone: %w", ErrAbortHandler))
+	})
+	h := router.Handler()
+
+	// 默认处理返回500.
+	ctx := newTestCtx("GET", "/users/1")
+	h(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusInternalServerError || string(ctx.Response.Body()) != "boom" {
+		t.Errorf("want 500 boom, got %d %q", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if log := logger.String(); !strings.HasPrefix(log, "panic serving GET /users/:id: boom\n") ||
+		!strings.Contains(log, "TestRouterRecover") {
+		t.Errorf("unexpected panic log %q", log)
+	}
+
+	// ErrAbortHandler 清空响应并关闭连接，不会交给 Recover 处理，也不会继续向上panic.
+	router.Recover = func(ctx *fasthttp.RequestCtx, info *PanicInfo) {
+		t.Errorf("Recover should not handle %v", info.Value)
+	}
+	logger.Reset()
+	ctx = newTestCtx("GET", "/abort")
+	ctx.SetBodyString("partial")
+	if recv := catchPanic(func() { h(ctx) }); recv != nil {
+		t.Errorf("ErrAbortHandler should not be re-panicked, got %v", recv)
+	}
+	if !ctx.Response.ConnectionClose() || len(ctx.Response.Body()) != 0 {
+		t.Errorf("want empty response with connection close, got close=%v body=%q",
+			ctx.Response.ConnectionClose(), ctx.Response.Body())
+	}
+	if logger.Len() != 0 {
+		t.Errorf("ErrAbortHandler should not be logged, got %q", logger.String())
+	}
+
+	var info *PanicInfo
+	router.Recover = func(ctx *fasthttp.RequestCtx, i *PanicInfo) {
+		info = i
+		ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+		ctx.SetBodyString("try again later")
+	}
+	ctx = newTestCtx("HEAD", "/users/2")
+	h(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable || string(ctx.Response.Body()) != "try again later" {
+		t.Errorf("want custom 503 response, got %d %q", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if info == nil || info.Value != "boom" || info.Route != "/users/:id" || info.Method != "HEAD" || len(info.Stack) == 0 {
+		t.Errorf("unexpected panic info %+v", info)
+	}
+}
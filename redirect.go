@@ -0,0 +1,119 @@
+package fastrouter
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// redirect 请求路径与注册的路由只相差末尾的"/"或大小写时，重定向到注册的路径，
+// GET请求返回301，其他请求返回308以保留请求方法和请求体.
+func (a *FastRouter) redirect(ctx *fasthttp.RequestCtx, t *routeTable, method string, urlPath []byte) bool {
+	if !a.RedirectTrailingSlash && !a.RedirectFixedPath || method == http.MethodConnect {
+		return false
+	}
+	roots := a.redirectTrees(t, method)
+	if len(roots) == 0 {
+		return false
+	}
+	if a.RedirectTrailingSlash && len(urlPath) > 1 {
+		var fixed []byte
+		if urlPath[len(urlPath)-1] == '/' {
+			fixed = []byte(strings.TrimRight(string(urlPath), URLSep))
+		} else {
+			fixed = append(append(make([]byte, 0, len(urlPath)+1), urlPath...), '/')
+		}
+		for _, root := range roots {
+			if r := root.lookup(fixed); r != nil && r.mount == nil {
+				redirectTo(ctx, method, fixed)
+				return true
+			}
+		}
+	}
+	if a.RedirectFixedPath {
+		for _, root := range roots {
+			if fixed, ok := root.findFold(urlPath, nil, a.RedirectTrailingSlash); ok && !bytes.Equal(fixed, urlPath) {
+				redirectTo(ctx, method, fixed)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redirectTrees 返回可以处理该请求方法的路由树，自动处理HEAD请求时包括GET路由树.
+func (a *FastRouter) redirectTrees(t *routeTable, method string) []*node {
+	var roots []*node
+	if root, ok := t.trees[method]; ok {
+		roots = append(roots, root)
+	}
+	if a.HandleHEAD && method == http.MethodHead {
+		if root, ok := t.trees[http.MethodGet]; ok {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+func redirectTo(ctx *fasthttp.RequestCtx, method string, urlPath []byte) {
+	code := http.StatusPermanentRedirect
+	if method == http.MethodGet {
+		code = http.StatusMovedPermanently
+	}
+	location := escapePath(urlPath)
+	if mountPrefix, ok := ctx.UserValue(MountPrefixKey).(string); ok {
+		location = escapePath([]byte(mountPrefix)) + location
+	}
+	if queryString := ctx.URI().QueryString(); len(queryString) > 0 {
+		location += "?" + string(queryString)
+	}
+	ctx.Response.Header.Set("Location", location)
+	ctx.SetStatusCode(code)
+}
+
+// findFold 忽略大小写匹配请求路径，匹配成功时返回使用注册时写法的路径，变量片段保留请求中的写法.
+// fixTrailingSlash 为true时同时修正末尾多余或缺少的"/".
+func (n *node) findFold(path []byte, buf []byte, fixTrailingSlash bool) ([]byte, bool) {
+	if len(path) == 0 {
+		if n.route != nil || n.prefix != nil {
+			return buf, true
+		}
+		if fixTrailingSlash {
+			if child, ok := n.static[URLSep]; ok && child.route != nil {
+				return append(buf, '/'), true
+			}
+		}
+		return nil, false
+	}
+	seg, rest := nextSegment(path)
+	if fixTrailingSlash && len(seg) == 1 && len(rest) == 0 && n.route != nil {
+		return buf, true
+	}
+	if child, ok := n.static[string(seg)]; ok {
+		if fixed, ok := child.findFold(rest, append(buf, seg...), fixTrailingSlash); ok {
+			return fixed, true
+		}
+	}
+	for key, child := range n.static {
+		if key == string(seg) || !strings.EqualFold(key, string(seg)) {
+			continue
+		}
+		if fixed, ok := child.findFold(rest, append(buf, key...), fixTrailingSlash); ok {
+			return fixed, true
+		}
+	}
+	for _, child := range n.params {
+		if !child.accept(seg) {
+			continue
+		}
+		if fixed, ok := child.findFold(rest, append(buf, seg...), fixTrailingSlash); ok {
+			return fixed, true
+		}
+	}
+	if n.catchAll != nil || n.prefix != nil || n.subPrefix != nil {
+		return append(buf, path...), true
+	}
+	return nil, false
+}
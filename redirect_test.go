@@ -0,0 +1,62 @@
+package fastrouter
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestRedirect(t *testing.T) {
+	handlerFunc := func(_ *fasthttp.RequestCtx) {}
+	router := NewRouter()
+	router.Get("/path", handlerFunc)
+	router.Get("/dir/", handlerFunc)
+	router.Post("/Users/:name/Repos", handlerFunc)
+	router.Get("/files/*filepath", handlerFunc)
+	h := router.Handler()
+
+	tests := []struct {
+		method   string
+		uri      string
+		code     int
+		location string
+	}{
+		{"GET", "/path", 200, ""},
+		{"GET", "/path/", 301, "/path"},
+		{"GET", "/dir", 301, "/dir/"},
+		{"GET", "/path/?a=1", 301, "/path?a=1"},
+		{"HEAD", "/path/", 308, "/path"},
+		{"POST", "/Users/gopher/Repos/", 308, "/Users/gopher/Repos"},
+		{"POST", "/users/Gopher/repos", 308, "/Users/Gopher/Repos"},
+		{"POST", "/USERS/gopher/REPOS/", 308, "/Users/gopher/Repos"},
+		{"GET", "/PATH", 301, "/path"},
+		{"GET", "/Dir", 301, "/dir/"},
+		{"GET", "/FILES/A/b", 301, "/files/A/b"},
+		{"PUT", "/path/", 404, ""},
+		{"GET", "/nope", 404, ""},
+		{"GET", "/", 404, ""},
+	}
+	check := func(code int, location string, method, uri string) {
+		ctx := newTestCtx(method, uri)
+		h(ctx)
+		if ctx.Response.StatusCode() != code {
+			t.Errorf("%s %s: want %d, got %d", method, uri, code, ctx.Response.StatusCode())
+		}
+		if got := string(ctx.Response.Header.Peek("Location")); got != location {
+			t.Errorf("%s %s: want Location %q, got %q", method, uri, location, got)
+		}
+	}
+	// 默认不重定向.
+	check(404, "", "GET", "/path/")
+	check(404, "", "GET", "/PATH")
+
+	router.RedirectTrailingSlash = true
+	router.RedirectFixedPath = true
+	for _, tt := range tests {
+		check(tt.code, tt.location, tt.method, tt.uri)
+	}
+
+	router.RedirectFixedPath = false
+	check(301, "/path", "GET", "/path/")
+	check(404, "", "GET", "/PATH")
+}
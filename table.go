@@ -0,0 +1,185 @@
+package fastrouter
+
+import (
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// routeTable 处理请求时使用的只读路由表，由注册的路由定义生成.
+// 路由表发布（Handler、Mount、Host、Compile）后，注册、删除路由等修改在持有锁的修改方中重新生成路由表并原子替换，
+// 处理请求时只读取当前的路由表，不需要加锁，也不会等待修改方.
+type routeTable struct {
+	trees         map[string]*node
+	methods       []string
+	mountTree     *node // 只包含挂载路由，用于没有注册过路由的请求方法
+	preHandlers   []PreHandler
+	afterHandlers []fasthttp.RequestHandler // 全局的后置处理函数
+	preRouting    []PreHandler
+	unmatched     fasthttp.RequestHandler // 使用全局中间件包装的 serveUnmatched
+	groups        []*Group
+	hosts         map[string]*FastRouter
+	hostPatterns  []*hostPattern
+}
+
+// loadTable 返回当前的路由表，路由表未发布时返回nil.
+func (a *FastRouter) loadTable() *routeTable {
+	t, _ := a.table.Load().(*routeTable)
+	return t
+}
+
+// publish 生成并发布路由表，之后的修改立即重新生成路由表. 发布前的修改不生成路由表，避免注册路由时重复生成.
+func (a *FastRouter) publish() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.published {
+		a.published = true
+		a.table.Store(a.buildTable())
+	}
+}
+
+// rebuild 路由表已发布时重新生成并原子替换，调用时需持有 a.mu.
+func (a *FastRouter) rebuild() {
+	if a.published {
+		a.table.Store(a.buildTable())
+	}
+}
+
+// buildTable 按注册顺序生成路由表，路由被复制到新的路由树中，路由定义之后的修改不会影响已生成的路由表.
+func (a *FastRouter) buildTable() *routeTable {
+	t := &routeTable{
+		trees:         map[string]*node{},
+		mountTree:     newNode(),
+		preHandlers:   append([]PreHandler(nil), a.preHandlers...),
+		afterHandlers: append([]fasthttp.RequestHandler(nil), a.afterHandlers...),
+		preRouting:    append([]PreHandler(nil), a.preRouting...),
+		groups:        append([]*Group(nil), a.groups...),
+		hosts:         make(map[string]*FastRouter, len(a.hosts)),
+		hostPatterns:  append([]*hostPattern(nil), a.hostPatterns...),
+	}
+	t.unmatched = a.unmatchedHandler(t)
+	for host, r := range a.hosts {
+		t.hosts[host] = r
+	}
+	for _, m := range a.mounts {
+		t.mountTree.insert(m)
+	}
+	sets := map[string]*methodSet{}
+	for _, r := range a.routes {
+		root, ok := t.trees[r.method]
+		if !ok {
+			root = newNode()
+			for _, m := range a.mounts {
+				root.insert(m)
+			}
+			t.trees[r.method] = root
+			t.methods = append(t.methods, r.method)
+		}
+		v := *r
+		if v.handler != nil {
+			v.handler = chain(chain(v.handler, v.middlewares), a.middlewares)
+		}
+		// 相同路径模式的路由共享允许的请求方法.
+		if set, ok := sets[v.pattern]; ok {
+			set.add(v.method)
+			v.allowMethods = set
+		} else {
+			v.allowMethods = newMethodSet(v.method)
+			sets[v.pattern] = v.allowMethods
+		}
+		root.insert(&v)
+	}
+	return t
+}
+
+// Remove 删除请求方法和路径与注册时相同的路由（包括同一路径的前缀路由），返回是否删除了路由.
+// 路由的名称同时失效. 可以在处理请求的同时调用，正在处理的请求仍使用删除前的路由表.
+func (a *FastRouter) Remove(method string, urlPath string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	path := strings.Join(splitPath(urlPath), "")
+	routes := a.routes[:0:0]
+	for _, r := range a.routes {
+		if r.method == method && strings.Join(r.deepPath, "") == path {
+			a.removeNames(r)
+			continue
+		}
+		routes = append(routes, r)
+	}
+	if len(routes) == len(a.routes) {
+		return false
+	}
+	a.routes = routes
+	a.trees = map[string]*node{}
+	for _, r := range a.routes {
+		a.tree(r.method).insert(r)
+	}
+	a.rebuild()
+	return true
+}
+
+// Replace 使用 r 的路由定义（路由、挂载、分组、全局PreHandler、中间件、前置和后置处理函数、主机路由和路由名称）整体替换当前路由，
+// 可以在处理请求的同时调用，替换是原子的. 替换后不应再通过 r 或其分组注册路由.
+func (a *FastRouter) Replace(r *FastRouter) {
+	if r == a {
+		return
+	}
+	r.mu.Lock()
+	routes := append([]*route(nil), r.routes...)
+	mounts := append([]*route(nil), r.mounts...)
+	groups := append([]*Group(nil), r.groups...)
+	preHandlers := append([]PreHandler(nil), r.preHandlers...)
+	middlewares := append([]Middleware(nil), r.middlewares...)
+	afterHandlers := append([]fasthttp.RequestHandler(nil), r.afterHandlers...)
+	preRouting := append([]PreHandler(nil), r.preRouting...)
+	hostPatterns := append([]*hostPattern(nil), r.hostPatterns...)
+	hosts := make(map[string]*FastRouter, len(r.hosts))
+	for host, v := range r.hosts {
+		hosts[host] = v
+	}
+	names := make(map[string]*route, len(r.names))
+	for name, v := range r.names {
+		names[name] = v
+	}
+	constraints := make(map[string]ConstraintFunc, len(r.constraints))
+	for name, v := range r.constraints {
+		constraints[name] = v
+	}
+	r.mu.Unlock()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.routes, a.mounts, a.groups, a.preHandlers, a.middlewares = routes, mounts, groups, preHandlers, middlewares
+	a.afterHandlers, a.preRouting = afterHandlers, preRouting
+	a.hosts, a.hostPatterns, a.names, a.constraints = hosts, hostPatterns, names, constraints
+	a.trees = map[string]*node{}
+	a.mountTree = newNode()
+	for _, m := range a.mounts {
+		a.mountTree.insert(m)
+	}
+	for _, v := range a.routes {
+		a.tree(v.method).insert(v)
+	}
+	a.rebuild()
+}
+
+// tree 返回请求方法对应的路由树，不存在时创建并挂载已有的子路由，调用时需持有 a.mu.
+func (a *FastRouter) tree(method string) *node {
+	root, ok := a.trees[method]
+	if !ok {
+		root = newNode()
+		for i := range a.mounts {
+			root.insert(a.mounts[i])
+		}
+		a.trees[method] = root
+	}
+	return root
+}
+
+func (a *FastRouter) removeNames(r *route) {
+	for name, v := range a.names {
+		if v == r {
+			delete(a.names, name)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package fastrouter
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestRouterRemove(t *testing.T) {
+	handlerFunc := func(_ *fasthttp.RequestCtx) {}
+	router := NewRouter()
+	router.Get("/users/:id", handlerFunc).Name("user.show")
+	router.Delete("/users/:id", handlerFunc)
+	router.PrefixHandler("GET", "/static/", handlerFunc)
+	router.Get("/static/", handlerFunc)
+	h := router.Handler()
+
+	check := func(method, uri string, code int, allow string) {
+		t.Helper()
+		ctx := newTestCtx(method, uri)
+		h(ctx)
+		if ctx.Response.StatusCode() != code {
+			t.Errorf("%s %s: want %d, got %d", method, uri, code, ctx.Response.StatusCode())
+		}
+		if got := string(ctx.Response.Header.Peek("Allow")); got != allow {
+			t.Errorf("%s %s: want Allow %q, got %q", method, uri, allow, got)
+		}
+	}
+	check("GET", "/users/1", 200, "")
+	check("PUT", "/users/1", 405, "DELETE, GET, HEAD, OPTIONS")
+
+	if router.Remove("POST", "/users/:id") {
+		t.Error("removing an unregistered route should return false")
+	}
+	if !router.Remove("GET", "/users/:id") {
+		t.Fatal("removing a registered route should return true")
+	}
+	check("GET", "/users/1", 405, "DELETE, OPTIONS")
+	check("DELETE", "/users/1", 200, "")
+	if _, err := router.URL("user.show", "id", "1"); err == nil {
+		t.Error("route name should be removed with the route")
+	}
+
+	// 同一路径的前缀路由和明确路由一起删除.
+	if !router.Remove("GET", "/static/") {
+		t.Fatal("removing a registered route should return true")
+	}
+	check("GET", "/static/", 404, "")
+	check("GET", "/static/app.js", 404, "")
+
+	// 删除后可以重新注册.
+	router.Get("/users/:id", handlerFunc)
+	check("GET", "/users/1", 200, "")
+}
+
+func TestRouterReplace(t *testing.T) {
+	router := NewRouter()
+	router.Get("/old", func(ctx *fasthttp.RequestCtx) {})
+	h := router.Handler()
+
+	next := NewRouter()
+	next.Use(func(ctx *fasthttp.RequestCtx) bool {
+		ctx.Response.Header.Set("X-Version", "2")
+		return true
+	})
+	next.Get("/new/:id<int>", func(ctx *fasthttp.RequestCtx) {}).Name("new")
+	sub := NewRouter()
+	sub.Get("/ping", func(ctx *fasthttp.RequestCtx) {})
+	next.Mount("/sub", sub)
+	router.Replace(next)
+
+	for uri, code := range map[string]int{"/old": 404, "/new/1": 200, "/new/a": 404, "/sub/ping": 200} {
+		ctx := newTestCtx("GET", uri)
+		h(ctx)
+		if ctx.Response.StatusCode() != code {
+			t.Errorf("GET %s: want %d, got %d", uri, code, ctx.Response.StatusCode())
+		}
+		if code == 200 && string(ctx.Response.Header.Peek("X-Version")) != "2" {
+			t.Errorf("GET %s: global PreHandler should be replaced", uri)
+		}
+	}
+	if u, err := router.URL("new", "id", "7"); err != nil || u != "/new/7" {
+		t.Errorf("URL: want /new/7, got %q %v", u, err)
+	}
+}
+
+func TestRouterConcurrentUpdate(t *testing.T) {
+	router := NewRouter()
+	router.Get("/stable", func(ctx *fasthttp.RequestCtx) {})
+	h := router.Handler()
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				for _, uri := range []string{"/stable", "/dynamic/1", "/dynamic/2/x", "/replaced"} {
+					ctx := newTestCtx("GET", uri)
+					h(ctx)
+					if uri == "/stable" && ctx.Response.StatusCode() != 200 {
+						t.Errorf("GET /stable: want 200, got %d", ctx.Response.StatusCode())
+						return
+					}
+				}
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		path := fmt.Sprintf("/dynamic/%d", i%3)
+		router.Get(path, func(ctx *fasthttp.RequestCtx) {})
+		router.PrefixHandler("GET", path+"/", func(ctx *fasthttp.RequestCtx) {})
+		router.Use(func(ctx *fasthttp.RequestCtx) bool { return true })
+		router.Remove("GET", path)
+		router.Remove("GET", path+"/")
+		if i%50 == 0 {
+			next := NewRouter()
+			next.Get("/stable", func(ctx *fasthttp.RequestCtx) {})
+			next.Get("/replaced", func(ctx *fasthttp.RequestCtx) {})
+			router.Replace(next)
+		}
+	}
+	close(done)
+	wg.Wait()
+}
+
+func TestRouterPublish(t *testing.T) {
+	handlerFunc := func(ctx *fasthttp.RequestCtx) {}
+	router := NewRouter()
+	router.Get("/a", handlerFunc)
+	if router.loadTable() != nil {
+		t.Fatal("route table should not be built before it is published")
+	}
+	h := router.Handler()
+	published := router.loadTable()
+	if published == nil || published.trees["GET"].lookup([]byte("/a")) == nil {
+		t.Fatal("Handler should publish the route table")
+	}
+	// 修改方立即生成新的路由表.
+	router.Get("/b", handlerFunc)
+	if next := router.loadTable(); next == published || next.trees["GET"].lookup([]byte("/b")) == nil {
+		t.Fatal("registering a route should publish a new route table")
+	}
+
+	// 修改方持有锁时，处理请求不会等待.
+	router.mu.Lock()
+	defer router.mu.Unlock()
+	served := make(chan int)
+	go func() {
+		ctx := newTestCtx("GET", "/b")
+		h(ctx)
+		served <- ctx.Response.StatusCode()
+	}()
+	select {
+	case code := <-served:
+		if code != 200 {
+			t.Errorf("GET /b: want 200, got %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("request blocked behind a writer")
+	}
+}
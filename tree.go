@@ -0,0 +1,181 @@
+package fastrouter
+
+import "fmt"
+
+// node 路由树节点，每个HTTP方法对应一棵树，按路径片段逐层匹配.
+type node struct {
+	static    map[string]*node
+	params    []*node        // 变量子节点，有约束的排在无约束的前面
+	expr      string         // 变量节点的约束表达式
+	check     ConstraintFunc // 变量节点的约束校验函数，为nil时匹配任意非空片段
+	route     *route         // 完整匹配当前节点路径的路由
+	prefix    *route         // 匹配以当前节点路径开头的前缀路由
+	subPrefix *route         // 以"/"结尾的前缀路由，要求请求路径比当前节点更深
+	catchAll  *route         // 以"*name"结尾的路由，捕获当前节点之后的全部路径
+	prefixes  int            // 子树中前缀路由的数量，用于匹配时剪枝
+}
+
+func newNode() *node {
+	return &node{static: map[string]*node{}}
+}
+
+func isParamSegment(seg string) bool {
+	return len(seg) > 1 && seg[1] == ':'
+}
+
+func isCatchAllSegment(seg string) bool {
+	return len(seg) > 1 && seg[1] == '*'
+}
+
+// insert 将路由挂载到树上，同一位置重复定义时panic.
+func (n *node) insert(r *route) {
+	if err := n.tryInsert(r); err != nil {
+		panic(err)
+	}
+}
+
+// tryInsert 将路由挂载到树上，同一位置重复定义时返回 ErrDuplicateRoute.
+func (n *node) tryInsert(r *route) error {
+	segs := r.deepPath
+	isSubPrefix := r.isPrefixHandler && segs[len(segs)-1] == URLSep
+	isCatchAll := isCatchAllSegment(segs[len(segs)-1])
+	if isSubPrefix || isCatchAll {
+		segs = segs[:len(segs)-1]
+	}
+	path := []*node{n}
+	vars := r.vars
+	for _, seg := range segs {
+		if isParamSegment(seg) {
+			n = n.paramChild(vars[0])
+			vars = vars[1:]
+		} else {
+			child, ok := n.static[seg]
+			if !ok {
+				child = newNode()
+				n.static[seg] = child
+			}
+			n = child
+		}
+		path = append(path, n)
+	}
+	slot := &n.route
+	if isSubPrefix {
+		slot = &n.subPrefix
+	} else if isCatchAll {
+		slot = &n.catchAll
+	} else if r.isPrefixHandler {
+		slot = &n.prefix
+	}
+	if *slot != nil {
+		return fmt.Errorf("%w: %s %s", ErrDuplicateRoute, r.method, r.urlPath)
+	}
+	*slot = r
+	if r.isPrefixHandler {
+		for i := range path {
+			path[i].prefixes++
+		}
+	}
+	return nil
+}
+
+// paramChild 返回约束表达式相同的变量子节点，不存在时创建.
+func (n *node) paramChild(v routeVar) *node {
+	for _, child := range n.params {
+		if child.expr == v.expr {
+			return child
+		}
+	}
+	child := newNode()
+	child.expr = v.expr
+	child.check = v.check
+	i := len(n.params)
+	if v.expr != "" {
+		for i > 0 && n.params[i-1].expr == "" {
+			i--
+		}
+	}
+	n.params = append(n.params, nil)
+	copy(n.params[i+1:], n.params[i:])
+	n.params[i] = child
+	return child
+}
+
+// accept 判断变量节点是否接受该路径片段.
+func (n *node) accept(seg []byte) bool {
+	return len(seg) > 1 && (n.check == nil || n.check(seg[1:]))
+}
+
+// lookup 查找匹配请求路径的路由，优先级为：明确路由 > 前缀路由 > 变量路由 > 通配路由.
+// 匹配直接在请求路径的字节切片上进行，不会产生内存分配.
+func (n *node) lookup(path []byte) *route {
+	if len(path) == 0 {
+		path = strSlash
+	}
+	r, hasVar := n.match(path)
+	if r != nil && !hasVar {
+		return r
+	}
+	if p, _ := n.matchPrefix(path, 0); p != nil {
+		return p
+	}
+	return r
+}
+
+// match 深度优先匹配完整路径，静态片段优先于变量片段（有约束的变量优先），变量片段优先于通配片段，
+// 返回的布尔值表示是否经过了变量或通配片段.
+func (n *node) match(path []byte) (*route, bool) {
+	if len(path) == 0 {
+		return n.route, false
+	}
+	seg, rest := nextSegment(path)
+	if child, ok := n.static[string(seg)]; ok {
+		if r, hasVar := child.match(rest); r != nil {
+			return r, hasVar
+		}
+	}
+	for _, child := range n.params {
+		if !child.accept(seg) {
+			continue
+		}
+		if r, _ := child.match(rest); r != nil {
+			return r, true
+		}
+	}
+	if n.catchAll != nil {
+		return n.catchAll, true
+	}
+	return nil, false
+}
+
+// matchPrefix 返回匹配请求路径的最深前缀路由及其深度，没有匹配时深度为-1.
+func (n *node) matchPrefix(path []byte, depth int) (*route, int) {
+	if n.prefixes == 0 {
+		return nil, -1
+	}
+	var best *route
+	bestDepth := -1
+	if n.prefix != nil {
+		best, bestDepth = n.prefix, depth
+	}
+	if n.subPrefix != nil && len(path) > 0 {
+		best, bestDepth = n.subPrefix, depth
+	}
+	if len(path) == 0 {
+		return best, bestDepth
+	}
+	seg, rest := nextSegment(path)
+	if child, ok := n.static[string(seg)]; ok {
+		if r, d := child.matchPrefix(rest, depth+1); d > bestDepth {
+			best, bestDepth = r, d
+		}
+	}
+	for _, child := range n.params {
+		if !child.accept(seg) {
+			continue
+		}
+		if r, d := child.matchPrefix(rest, depth+1); d > bestDepth {
+			best, bestDepth = r, d
+		}
+	}
+	return best, bestDepth
+}
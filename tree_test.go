@@ -0,0 +1,117 @@
+package fastrouter
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestTreeLookup(t *testing.T) {
+	router := NewRouter()
+	handlerFunc := func(_ *fasthttp.RequestCtx) {}
+	router.Get("/", handlerFunc)
+	router.Get("/a", handlerFunc)
+	router.Get("/a/b", handlerFunc)
+	router.Get("/:a/:b", handlerFunc)
+	router.Get("/a/:y", handlerFunc)
+	router.Get("/a/:y/c", handlerFunc)
+	router.Get("/a/:y/", handlerFunc)
+	router.PrefixHandler("GET", "/p", handlerFunc)
+	router.PrefixHandler("GET", "/p/q/", handlerFunc)
+	router.PrefixHandler("GET", "/v/:b/:c", handlerFunc)
+	router.Get("/files/*filepath", handlerFunc)
+	router.Get("/files/:name", handlerFunc)
+	router.Get("/files/static/", handlerFunc)
+	router.Get("/p/*rest", handlerFunc)
+
+	tests := map[string]string{
+		"/":               "/",
+		"/a":              "/a",
+		"/a/b":            "/a/b",
+		"/a/x":            "/a/:y",
+		"/a/x/":           "/a/:y/",
+		"/a/x/c":          "/a/:y/c",
+		"/b/x":            "/:a/:b",
+		"/p":              "/p",
+		"/p/x/y":          "/p",
+		"/p/q":            "/p",
+		"/p/q/":           "/p/q/",
+		"/p/q/z":          "/p/q/",
+		"/v/x/y/z":        "/v/:b/:c",
+		"/v/x":            "/:a/:b",
+		"/files/":         "/files/*filepath",
+		"/files/a":        "/files/:name",
+		"/files/a/b/c":    "/files/*filepath",
+		"/files/static/":  "/files/static/",
+		"/files/static/x": "/files/*filepath",
+		"/p/r/s":          "/p",
+		"/files":          "",
+		"/ab":             "",
+		"/a/x/d":          "",
+		"/b/x/y":          "",
+		"/a/b/c/d/":       "",
+	}
+	root := router.trees["GET"]
+	for path, want := range tests {
+		r := root.lookup([]byte(path))
+		var got string
+		if r != nil {
+			got = r.urlPath
+		}
+		if got != want {
+			t.Errorf("lookup %s: want %q, got %q", path, want, got)
+		}
+	}
+}
+
+func TestTreeDuplicate(t *testing.T) {
+	router := NewRouter()
+	handlerFunc := func(_ *fasthttp.RequestCtx) {}
+	router.Get("/user/:name", handlerFunc)
+	router.Post("/user/:id", handlerFunc)
+	router.PrefixHandler("GET", "/user/:name", handlerFunc)
+
+	recv := catchPanic(func() {
+		router.Get("/user/:id", handlerFunc)
+	})
+	if recv == nil {
+		t.Fatal("registering duplicate route did not panic")
+	}
+	router.Handler()
+	allows := router.loadTable().trees["POST"].lookup([]byte("/user/x")).allowMethods.methods
+	if _, ok := allows["GET"]; !ok || len(allows) != 2 {
+		t.Fatalf("wrong allow methods: %v", allows)
+	}
+}
+
+func TestCatchAll(t *testing.T) {
+	router := NewRouter()
+	var filepath interface{}
+	router.Get("/files/*filepath", func(ctx *fasthttp.RequestCtx) {
+		filepath = ctx.UserValue("filepath")
+	})
+	handler := router.Handler()
+	tests := map[string]string{
+		"/files/":           "",
+		"/files/a.txt":      "a.txt",
+		"/files/a/b/c.txt":  "a/b/c.txt",
+		"/files/dir/":       "dir/",
+		"/files//double//x": "double/x",
+	}
+	for uri, want := range tests {
+		filepath = nil
+		handler(newTestCtx("GET", uri))
+		if filepath != want {
+			t.Errorf("GET %s: want filepath %q, got %v", uri, want, filepath)
+		}
+	}
+
+	for _, path := range []string{"/a/*b/c", "/a/*"} {
+		if recv := catchPanic(func() { router.Get(path, nil) }); recv == nil {
+			t.Errorf("registering %s did not panic", path)
+		}
+	}
+	if recv := catchPanic(func() { router.PrefixHandler("GET", "/a/*b", nil) }); recv == nil {
+		t.Error("registering catch-all prefix route did not panic")
+	}
+}
@@ -0,0 +1,110 @@
+package fastrouter
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Route 已注册路由的句柄.
+type Route struct {
+	router *FastRouter
+	route  *route
+	others []*route // Any 同时注册的其他请求方法的路由，Use 和 After 同样作用于这些路由
+}
+
+// routes 返回句柄对应的全部路由.
+func (r *Route) routes() []*route {
+	return append([]*route{r.route}, r.others...)
+}
+
+// Name 为路由命名，命名后可以通过 FastRouter.URL 生成该路由的URL，名称重复时panic.
+func (r *Route) Name(name string) *Route {
+	a := r.router
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if name == "" {
+		panic("route name cannot be empty")
+	}
+	if v, ok := a.names[name]; ok && v != r.route {
+		panic(fmt.Sprintf("route name already exist : %s %s", name, v.urlPath))
+	}
+	a.names[name] = r.route
+	return r
+}
+
+// URL 根据路由名称和变量生成URL，params 为变量名与变量值交替排列的列表，
+// 例如 URL("user.show", "id", "42") 由 "/users/:id" 生成 "/users/42".
+// 变量值会按路径片段转义，缺少变量或变量值不满足约束时返回错误，多余的变量会被忽略.
+// 挂载的子路由中的命名路由会加上挂载前缀.
+func (a *FastRouter) URL(name string, params ...string) (string, error) {
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("fastrouter: odd number of URL parameters for route %s", name)
+	}
+	a.mu.Lock()
+	r, ok := a.names[name]
+	mounts := a.mounts
+	a.mu.Unlock()
+	if ok {
+		return buildURL(r, params)
+	}
+	for _, m := range mounts {
+		sub, err := m.mount.URL(name, params...)
+		if err == errRouteNameNotFound {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		prefix, err := buildURL(m, params)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(prefix, URLSep) + sub, nil
+	}
+	return "", errRouteNameNotFound
+}
+
+var errRouteNameNotFound = fmt.Errorf("fastrouter: route name not found")
+
+// buildURL 按路由的路径片段填充变量值.
+func buildURL(r *route, params []string) (string, error) {
+	var b strings.Builder
+	vars := r.vars
+	for i, seg := range r.deepPath {
+		if len(vars) == 0 || vars[0].index != i {
+			b.WriteString(seg)
+			continue
+		}
+		v := vars[0]
+		vars = vars[1:]
+		value, ok := paramValue(params, v.name)
+		if !ok {
+			return "", fmt.Errorf("fastrouter: missing parameter %s for route %s", v.name, r.urlPath)
+		}
+		b.WriteString(URLSep)
+		if v.catchAll {
+			// 通配值可以以"/"开头，去掉后再拼接，避免生成 "//".
+			parts := strings.Split(strings.TrimPrefix(value, URLSep), URLSep)
+			for j := range parts {
+				parts[j] = url.PathEscape(parts[j])
+			}
+			b.WriteString(strings.Join(parts, URLSep))
+			continue
+		}
+		if value == "" || (v.check != nil && !v.check([]byte(value))) {
+			return "", fmt.Errorf("fastrouter: parameter %s=%q does not match route %s", v.name, value, r.urlPath)
+		}
+		b.WriteString(url.PathEscape(value))
+	}
+	return b.String(), nil
+}
+
+func paramValue(params []string, name string) (string, bool) {
+	for i := 0; i+1 < len(params); i += 2 {
+		if params[i] == name {
+			return params[i+1], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,61 @@
+package fastrouter
+
+import "testing"
+
+func TestURL(t *testing.T) {
+	router := NewRouter()
+	router.Get("/", nil).Name("index")
+	router.Get("/users/:id<int>", nil).Name("user.show")
+	router.Get("/users/:id<int>/posts/:slug", nil).Name("user.post")
+	router.Get("/files/*filepath", nil).Name("files")
+	router.Group("/admin").Any("/:page/", nil).Name("admin.page")
+	sub := NewRouter()
+	sub.Get("/:id", nil).Name("item.show")
+	router.Mount("/shops/:shop/items", sub)
+
+	tests := []struct {
+		name   string
+		params []string
+		want   string
+	}{
+		{"index", nil, "/"},
+		{"user.show", []string{"id", "42"}, "/users/42"},
+		{"user.post", []string{"slug", "hello world/中文", "id", "7"}, "/users/7/posts/hello%20world%2F%E4%B8%AD%E6%96%87"},
+		{"files", []string{"filepath", "a b/c.txt"}, "/files/a%20b/c.txt"},
+		{"files", []string{"filepath", ""}, "/files/"},
+		{"files", []string{"filepath", "/a/b"}, "/files/a/b"},
+		{"admin.page", []string{"page", "home"}, "/admin/home/"},
+		{"item.show", []string{"shop", "s1", "id", "9"}, "/shops/s1/items/9"},
+	}
+	for _, tt := range tests {
+		got, err := router.URL(tt.name, tt.params...)
+		if err != nil {
+			t.Errorf("URL(%s, %v): unexpected error %s", tt.name, tt.params, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("URL(%s, %v): want %s, got %s", tt.name, tt.params, tt.want, got)
+		}
+	}
+
+	errors := []struct {
+		name   string
+		params []string
+	}{
+		{"unknown", nil},
+		{"user.show", nil},
+		{"user.show", []string{"id"}},
+		{"user.show", []string{"id", "abc"}},
+		{"user.post", []string{"id", "1", "slug", ""}},
+		{"item.show", []string{"id", "9"}},
+	}
+	for _, tt := range errors {
+		if got, err := router.URL(tt.name, tt.params...); err == nil {
+			t.Errorf("URL(%s, %v): want error, got %s", tt.name, tt.params, got)
+		}
+	}
+
+	if recv := catchPanic(func() { router.Get("/other", nil).Name("index") }); recv == nil {
+		t.Error("registering duplicate route name did not panic")
+	}
+}
@@ -0,0 +1,346 @@
+package fastrouter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ConflictKind 路由冲突的类型.
+type ConflictKind string
+
+const (
+	// ConflictAmbiguous 两个路由能匹配相同的路径，但互不包含，匹配哪一个取决于优先级规则.
+	ConflictAmbiguous ConflictKind = "ambiguous"
+	// ConflictShadowed 路由的部分路径被其他带变量的路由或前缀路由优先匹配.
+	ConflictShadowed ConflictKind = "shadowed"
+	// ConflictUnreachable 路由的全部路径都被其他路由优先匹配，该路由永远不会被执行.
+	ConflictUnreachable ConflictKind = "unreachable"
+)
+
+// RouteConflict Validate 发现的路由冲突，Route 为受影响的路由，By 为优先匹配的路由.
+type RouteConflict struct {
+	Kind  ConflictKind
+	Route string
+	By    string
+}
+
+func (c *RouteConflict) Error() string {
+	switch c.Kind {
+	case ConflictAmbiguous:
+		return fmt.Sprintf("ambiguous routes: %s and %s match some of the same paths, %s takes precedence", c.Route, c.By, c.By)
+	case ConflictShadowed:
+		return fmt.Sprintf("route %s is shadowed by %s for some paths", c.Route, c.By)
+	default:
+		return fmt.Sprintf("route %s is unreachable, all its paths are handled by %s", c.Route, c.By)
+	}
+}
+
+// RouteErrors Validate 返回的错误列表.
+type RouteErrors []error
+
+func (e RouteErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Validate 检查已注册的路由，返回有歧义、被部分遮蔽和无法到达的路由，没有问题时返回nil.
+// 明确路由优先于变量路由是预期的行为，不会被报告；带变量的路由被另一个带变量的路由遮蔽部分路径时才会报告.
+// 挂载的子路由和主机路由同时被检查. 返回的错误为 RouteErrors，元素通常为 *RouteConflict.
+func (a *FastRouter) Validate() error {
+	a.mu.Lock()
+	routes := append([]*route(nil), a.routes...)
+	mounts := append([]*route(nil), a.mounts...)
+	hosts := make([]string, 0, len(a.hosts)+len(a.hostPatterns))
+	hostRouters := make([]*FastRouter, 0, cap(hosts))
+	for host, r := range a.hosts {
+		hosts = append(hosts, host)
+		hostRouters = append(hostRouters, r)
+	}
+	for _, h := range a.hostPatterns {
+		hosts = append(hosts, h.pattern)
+		hostRouters = append(hostRouters, h.router)
+	}
+	a.mu.Unlock()
+
+	var errs RouteErrors
+	for i, r := range routes {
+		if r.isPrefixHandler {
+			continue
+		}
+		for _, v := range routes[i+1:] {
+			if v.method == r.method && !v.isPrefixHandler {
+				if c := conflictBetween(r, v); c != nil {
+					errs = append(errs, c)
+				}
+			}
+		}
+	}
+	for _, r := range routes {
+		if r.isPrefixHandler || len(r.vars) == 0 {
+			continue
+		}
+		for _, p := range routes {
+			if p.isPrefixHandler && p.method == r.method {
+				if c := shadowedByPrefix(r, p); c != nil {
+					errs = append(errs, c)
+				}
+			}
+		}
+		for _, m := range mounts {
+			if c := shadowedByPrefix(r, m); c != nil {
+				errs = append(errs, c)
+			}
+		}
+	}
+	for _, m := range mounts {
+		if err := m.mount.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("mount %s: %w", m.urlPath, err))
+		}
+	}
+	for i, r := range hostRouters {
+		if err := r.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("host %s: %w", hosts[i], err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Compile 检查路由并发布路由表，返回值与 Validate 相同.
+func (a *FastRouter) Compile() error {
+	err := a.Validate()
+	a.publish()
+	return err
+}
+
+// describe 返回用于错误信息的路由描述.
+func (v *route) describe() string {
+	switch {
+	case v.mount != nil:
+		return "mount " + v.urlPath
+	case v.isPrefixHandler:
+		return v.method + " " + v.urlPath + " (prefix)"
+	default:
+		return v.method + " " + v.urlPath
+	}
+}
+
+// patternSeg 用于比较的路径模式片段.
+type patternSeg struct {
+	value    string // 明确片段，包含前导"/"
+	param    bool
+	catchAll bool
+	expr     string
+	check    ConstraintFunc
+}
+
+// rank 返回片段在同一节点下的匹配优先级，数值越小越优先.
+func (s patternSeg) rank() int {
+	switch {
+	case s.catchAll:
+		return 3
+	case s.param && s.expr == "":
+		return 2
+	case s.param:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (s patternSeg) accept(value string) bool {
+	return len(value) > 1 && (s.check == nil || s.check([]byte(value[1:])))
+}
+
+// routeSegments 返回路由的路径模式片段，以"/"结尾的前缀路由去除最后的"/"片段并返回true.
+func routeSegments(v *route) ([]patternSeg, bool) {
+	segs := make([]patternSeg, len(v.deepPath))
+	for i, seg := range v.deepPath {
+		segs[i].value = seg
+	}
+	for _, rv := range v.vars {
+		segs[rv.index] = patternSeg{param: !rv.catchAll, catchAll: rv.catchAll, expr: rv.expr, check: rv.check}
+	}
+	if v.isPrefixHandler && len(segs) > 1 && v.deepPath[len(segs)-1] == URLSep {
+		return segs[:len(segs)-1], true
+	}
+	return segs, false
+}
+
+// 两个片段匹配的值集合之间的关系.
+const (
+	relDisjoint = iota
+	relEqual
+	relSubset   // a 包含于 b
+	relSuperset // a 包含 b
+	relOverlap  // 有交集但互不包含
+)
+
+// builtinRelations 不同内置约束匹配的值集合之间的关系，未列出的两个不同内置约束互不相交.
+var builtinRelations = map[[2]string]int{
+	{"uint", "int"}:    relSubset,
+	{"uint", "alnum"}:  relSubset,
+	{"uint", "hex"}:    relSubset,
+	{"alpha", "alnum"}: relSubset,
+	{"hex", "alnum"}:   relSubset,
+	{"int", "alnum"}:   relOverlap, // "1"
+	{"int", "hex"}:     relOverlap, // "1"
+	{"alpha", "hex"}:   relOverlap, // "a"
+}
+
+// builtinConstraint 判断片段是否使用未被 RegisterConstraint 覆盖的内置约束.
+func (s patternSeg) builtinConstraint() bool {
+	match, ok := defaultConstraints[s.expr]
+	return ok && reflect.ValueOf(match).Pointer() == reflect.ValueOf(s.check).Pointer()
+}
+
+// compareConstraints 比较两个不同约束匹配的值集合. 只有内置约束之间的关系是确定的，
+// 正则表达式和自定义约束无法判断，保守地视为有交集.
+func compareConstraints(a, b patternSeg) int {
+	if !a.builtinConstraint() || !b.builtinConstraint() {
+		return relOverlap
+	}
+	if rel, ok := builtinRelations[[2]string{a.expr, b.expr}]; ok {
+		return rel
+	}
+	switch builtinRelations[[2]string{b.expr, a.expr}] {
+	case relSubset:
+		return relSuperset
+	case relOverlap:
+		return relOverlap
+	}
+	return relDisjoint
+}
+
+func compareSeg(a, b patternSeg) int {
+	switch {
+	case a.catchAll && b.catchAll:
+		return relEqual
+	case a.catchAll:
+		return relSuperset
+	case b.catchAll:
+		return relSubset
+	case !a.param && !b.param:
+		if a.value == b.value {
+			return relEqual
+		}
+		return relDisjoint
+	case !a.param:
+		if b.accept(a.value) {
+			return relSubset
+		}
+		return relDisjoint
+	case !b.param:
+		if a.accept(b.value) {
+			return relSuperset
+		}
+		return relDisjoint
+	case a.expr == b.expr:
+		return relEqual
+	case b.expr == "":
+		return relSubset
+	case a.expr == "":
+		return relSuperset
+	}
+	return compareConstraints(a, b)
+}
+
+// conflictBetween 比较同一请求方法下两个非前缀路由，r 先于 v 注册.
+func conflictBetween(r, v *route) *RouteConflict {
+	a, _ := routeSegments(r)
+	b, _ := routeSegments(v)
+	aSubB, bSubA := true, true
+	diff := -1
+	staticCarve := false // 优先的路由在某个位置使用明确片段，而另一个路由使用变量
+	for i := 0; ; i++ {
+		if i == len(a) || i == len(b) {
+			if len(a) != len(b) {
+				return nil
+			}
+			break
+		}
+		rel := compareSeg(a[i], b[i])
+		if rel == relDisjoint {
+			return nil
+		}
+		if rel != relEqual && diff < 0 {
+			diff = i
+		}
+		if rel != relEqual && (a[i].rank() == 0 || b[i].rank() == 0) {
+			staticCarve = true
+		}
+		aSubB = aSubB && (rel == relEqual || rel == relSubset)
+		bSubA = bSubA && (rel == relEqual || rel == relSuperset)
+		if a[i].catchAll || b[i].catchAll {
+			if !(a[i].catchAll && b[i].catchAll) {
+				// 通配片段可以匹配任意深度，另一个路由只匹配固定深度.
+				aSubB = aSubB && b[i].catchAll
+				bSubA = bSubA && a[i].catchAll
+			}
+			break
+		}
+	}
+	if diff < 0 {
+		return nil
+	}
+	winner, loser := r, v
+	winnerSub, loserSub := aSubB, bSubA
+	if b[diff].rank() < a[diff].rank() {
+		winner, loser = v, r
+		winnerSub, loserSub = bSubA, aSubB
+	}
+	switch {
+	case loserSub:
+		return &RouteConflict{Kind: ConflictUnreachable, Route: loser.describe(), By: winner.describe()}
+	case winnerSub:
+		if len(winner.vars) == 0 || !staticCarve {
+			// 明确路由或约束更严格的变量优先匹配是预期的行为.
+			return nil
+		}
+		return &RouteConflict{Kind: ConflictShadowed, Route: loser.describe(), By: winner.describe()}
+	default:
+		return &RouteConflict{Kind: ConflictAmbiguous, Route: loser.describe(), By: winner.describe()}
+	}
+}
+
+// shadowedByPrefix 检查带变量的路由 r 是否被前缀路由或挂载路由 p 优先匹配，
+// 请求路径经过变量片段时，能匹配的前缀路由优先于变量路由.
+func shadowedByPrefix(r, p *route) *RouteConflict {
+	segs, _ := routeSegments(r)
+	prefix, deeper := routeSegments(p)
+	contained := true
+	for i, ps := range prefix {
+		if i == len(segs) {
+			return nil
+		}
+		if segs[i].catchAll {
+			// 通配片段同时匹配比前缀更短的路径.
+			contained = false
+			break
+		}
+		switch compareSeg(segs[i], ps) {
+		case relDisjoint:
+			return nil
+		case relEqual, relSubset:
+		default:
+			contained = false
+		}
+	}
+	if deeper && len(segs) == len(prefix) {
+		if !segs[len(segs)-1].catchAll {
+			return nil
+		}
+		contained = false
+	}
+	kind := ConflictShadowed
+	if contained {
+		kind = ConflictUnreachable
+	}
+	return &RouteConflict{Kind: kind, Route: r.describe(), By: p.describe()}
+}
@@ -0,0 +1,133 @@
+package fastrouter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestValidate(t *testing.T) {
+	handlerFunc := func(_ *fasthttp.RequestCtx) {}
+
+	tests := []struct {
+		name     string
+		register func(router *FastRouter)
+		want     []RouteConflict
+	}{
+		{"static over variable", func(router *FastRouter) {
+			router.Get("/users/:id", handlerFunc)
+			router.Get("/users/me", handlerFunc)
+			router.Get("/users/:id<int>", handlerFunc)
+			router.Get("/files/:name", handlerFunc)
+			router.Get("/files/*filepath", handlerFunc)
+			router.Post("/:a/:b", handlerFunc)
+		}, nil},
+		{"shadowed", func(router *FastRouter) {
+			router.Get("/:a/:b", handlerFunc)
+			router.Get("/a/:y", handlerFunc)
+		}, []RouteConflict{{ConflictShadowed, "GET /:a/:b", "GET /a/:y"}}},
+		{"ambiguous", func(router *FastRouter) {
+			router.Get("/a/:y", handlerFunc)
+			router.Get("/:x/b", handlerFunc)
+		}, []RouteConflict{{ConflictAmbiguous, "GET /:x/b", "GET /a/:y"}}},
+		{"ambiguous constraints", func(router *FastRouter) {
+			router.Get("/n/:id<int>", handlerFunc)
+			router.Get("/n/:h<hex>", handlerFunc)
+			router.Get("/n/:s<alpha>/x", handlerFunc)
+			router.Get("/n/:i<int>/x", handlerFunc)
+		}, []RouteConflict{{ConflictAmbiguous, "GET /n/:h<hex>", "GET /n/:id<int>"}}},
+		{"builtin constraints", func(router *FastRouter) {
+			router.Get("/b/:u<uint>", handlerFunc)
+			router.Get("/b/:i<int>", handlerFunc)
+			router.Get("/c/:s<alpha>", handlerFunc)
+			router.Get("/c/:u<uuid>", handlerFunc)
+			router.Get("/e/:i<int>", handlerFunc)
+			router.Get("/e/:u<uint>", handlerFunc)
+		}, []RouteConflict{{ConflictUnreachable, "GET /e/:u<uint>", "GET /e/:i<int>"}}},
+		{"regexp and custom constraints", func(router *FastRouter) {
+			router.RegisterConstraint("lang", func(value []byte) bool {
+				return string(value) == "en" || string(value) == "zh"
+			})
+			router.Get("/a/:x<[0-9]{5}>", handlerFunc)
+			router.Get("/a/:y<int>", handlerFunc)
+			router.Get("/d/:l<lang>", handlerFunc)
+			router.Get("/d/:s<alpha>", handlerFunc)
+		}, []RouteConflict{
+			{ConflictAmbiguous, "GET /a/:y<int>", "GET /a/:x<[0-9]{5}>"},
+			{ConflictAmbiguous, "GET /d/:s<alpha>", "GET /d/:l<lang>"},
+		}},
+		{"prefix", func(router *FastRouter) {
+			router.PrefixHandler("GET", "/admin", handlerFunc)
+			router.Get("/admin/:page", handlerFunc)
+			router.Get("/admin/users", handlerFunc)
+			router.Get("/:section/list/all", handlerFunc)
+			router.Post("/admin/:page", handlerFunc)
+		}, []RouteConflict{
+			{ConflictUnreachable, "GET /admin/:page", "GET /admin (prefix)"},
+			{ConflictShadowed, "GET /:section/list/all", "GET /admin (prefix)"},
+		}},
+		{"sub prefix", func(router *FastRouter) {
+			router.PrefixHandler("GET", "/static/", handlerFunc)
+			router.Get("/:dir", handlerFunc)
+			router.Get("/:dir/*filepath", handlerFunc)
+		}, []RouteConflict{{ConflictShadowed, "GET /:dir/*filepath", "GET /static/ (prefix)"}}},
+		{"mount", func(router *FastRouter) {
+			router.Mount("/api", NewRouter())
+			router.Delete("/:name/:id", handlerFunc)
+		}, []RouteConflict{{ConflictShadowed, "DELETE /:name/:id", "mount /api"}}},
+	}
+	for _, tt := range tests {
+		router := NewRouter()
+		tt.register(router)
+		err := router.Validate()
+		if len(tt.want) == 0 {
+			if err != nil {
+				t.Errorf("%s: want no error, got %v", tt.name, err)
+			}
+			continue
+		}
+		var errs RouteErrors
+		if !errors.As(err, &errs) || len(errs) != len(tt.want) {
+			t.Errorf("%s: want %d conflicts, got %v", tt.name, len(tt.want), err)
+			continue
+		}
+		for i := range tt.want {
+			var c *RouteConflict
+			if !errors.As(errs[i], &c) || *c != tt.want[i] {
+				t.Errorf("%s: want %+v, got %v", tt.name, tt.want[i], errs[i])
+			}
+		}
+	}
+}
+
+func TestValidateNested(t *testing.T) {
+	handlerFunc := func(_ *fasthttp.RequestCtx) {}
+	sub := NewRouter()
+	sub.Get("/:a/:b", handlerFunc)
+	sub.Get("/a/:y", handlerFunc)
+	router := NewRouter()
+	router.Mount("/api", sub)
+	host := router.Host("admin.example.com")
+	host.PrefixHandler("GET", "/", handlerFunc)
+	host.PrefixHandler("GET", "/x", handlerFunc)
+	host.Get("/x/:id", handlerFunc)
+
+	err := router.Compile()
+	if err == nil {
+		t.Fatal("want conflicts in mounted and host routers")
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		"mount /api: route GET /:a/:b is shadowed by GET /a/:y for some paths",
+		"host admin.example.com: route GET /x/:id is unreachable, all its paths are handled by GET /x (prefix)",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("want %q in %q", want, msg)
+		}
+	}
+	if t2, _ := router.table.Load().(*routeTable); t2 == nil {
+		t.Error("Compile should build the route table")
+	}
+}